@@ -2,12 +2,57 @@ package config
 
 import (
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// RateLimit is a token bucket: Requests tokens, fully refilled every Per.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
 var (
 	DSN       string
 	JWTSecret string
+	AppURL    string // public base URL used in links we send out
+
+	// Accounts
+	EmailVerifyTTL       time.Duration
+	AccountDeletionGrace time.Duration
+
+	// Archived stories older than this are purged (0 = keep forever)
+	StoryArchiveRetention time.Duration
+
+	// How long a removed follower can't follow again (when asked for)
+	FollowerRemovalCooldown time.Duration
+
+	// Follow suggestions cache lifetime
+	SuggestionsTTL time.Duration
+
+	// Authors with more followers than this aren't fanned out on write;
+	// their followers read their posts and stories at request time
+	FanoutFollowerThreshold int
+
+	// Personal data exports
+	ExportDir     string
+	ExportLinkTTL time.Duration
+
+	// Users promoted to admin at startup while no admin exists
+	// (ADMIN_USER_IDS="1,2"), so a fresh deployment has someone who can
+	// assign roles
+	AdminUserIDs map[uint]bool
+
+	// Rate limiting
+	RateLimitStore string               // "memory" | "postgres"
+	RateLimits     map[string]RateLimit // keyed by route group
+
+	// Proxies whose X-Forwarded-For is believed (TRUSTED_PROXIES="10.0.0.0/8").
+	// Empty means the client IP is the socket peer.
+	TrustedProxies []*net.IPNet
 )
 
 func LoadConfig() {
@@ -25,5 +70,115 @@ func LoadConfig() {
 		JWTSecret = "supersecretkey"
 	}
 
+	AppURL = os.Getenv("APP_URL")
+	if AppURL == "" {
+		AppURL = "http://localhost:8080"
+	}
+
+	// Accounts
+	EmailVerifyTTL = getEnvDuration("EMAIL_VERIFY_TTL", 24*time.Hour)
+	AccountDeletionGrace = getEnvDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour)
+
+	StoryArchiveRetention = getEnvDuration("STORY_ARCHIVE_RETENTION", 0)
+	FollowerRemovalCooldown = getEnvDuration("FOLLOWER_REMOVAL_COOLDOWN", 30*24*time.Hour)
+	SuggestionsTTL = getEnvDuration("SUGGESTIONS_TTL", 6*time.Hour)
+	FanoutFollowerThreshold = getEnvInt("FANOUT_FOLLOWER_THRESHOLD", 10000)
+
+	// Personal data exports
+	ExportDir = os.Getenv("EXPORT_DIR")
+	if ExportDir == "" {
+		ExportDir = "exports"
+	}
+	ExportLinkTTL = getEnvDuration("EXPORT_LINK_TTL", 48*time.Hour)
+
+	AdminUserIDs = map[uint]bool{}
+	for _, raw := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			log.Printf("⚠️ invalid admin user id %q, skipping", raw)
+			continue
+		}
+		AdminUserIDs[uint(id)] = true
+	}
+
+	// Rate limiting: use "postgres" when running more than one replica
+	RateLimitStore = os.Getenv("RATE_LIMIT_STORE")
+	if RateLimitStore == "" {
+		RateLimitStore = "memory"
+	}
+	RateLimits = map[string]RateLimit{
+		"auth":    getEnvRateLimit("RATE_LIMIT_AUTH", RateLimit{Requests: 10, Per: time.Minute}),
+		"follow":  getEnvRateLimit("RATE_LIMIT_FOLLOW", RateLimit{Requests: 30, Per: time.Minute}),
+		"stories": getEnvRateLimit("RATE_LIMIT_STORIES", RateLimit{Requests: 120, Per: time.Minute}),
+	}
+
+	TrustedProxies = nil
+	for _, raw := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(raw)
+		if err != nil {
+			log.Printf("⚠️ invalid trusted proxy %q, skipping", raw)
+			continue
+		}
+		TrustedProxies = append(TrustedProxies, ipNet)
+	}
+
 	log.Println("✅ Config loaded")
 }
+
+// -------------------- Helpers --------------------
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️ invalid %s=%q, using default", key, val)
+		return def
+	}
+	return d
+}
+
+func getEnvInt(key string, def int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n <= 0 {
+		log.Printf("⚠️ invalid %s=%q, using default", key, val)
+		return def
+	}
+	return n
+}
+
+// getEnvRateLimit parses values like "10/1m" (requests/duration).
+func getEnvRateLimit(key string, def RateLimit) RateLimit {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	parts := strings.SplitN(val, "/", 2)
+	if len(parts) != 2 {
+		log.Printf("⚠️ invalid %s=%q, using default", key, val)
+		return def
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n <= 0 {
+		log.Printf("⚠️ invalid %s=%q, using default", key, val)
+		return def
+	}
+	per, err := time.ParseDuration(parts[1])
+	if err != nil || per <= 0 {
+		log.Printf("⚠️ invalid %s=%q, using default", key, val)
+		return def
+	}
+	return RateLimit{Requests: n, Per: per}
+}
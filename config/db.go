@@ -18,9 +18,150 @@ func ConnectDB() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	if err := DB.AutoMigrate(&models.User{}, &models.Story{}, &models.StoryView{}, &models.Follow{}, &models.FollowRequest{}); err != nil {
+	// Posts created before carousels need their single media item
+	hadPostMedia := DB.Migrator().HasTable(&models.PostMedia{})
+
+	// Content published before timelines existed is read at request time
+	hadTimelines := DB.Migrator().HasTable(&models.FeedItem{})
+
+	if err := DB.AutoMigrate(
+		&models.User{},
+		&models.Post{},
+		&models.PostMedia{},
+		&models.Story{},
+		&models.StoryView{},
+		&models.StoryEvent{},
+		&models.Follow{},
+		&models.FollowRequest{},
+		&models.RateLimitBucket{},
+		&models.Notification{},
+		&models.DataExport{},
+		&models.Block{},
+		&models.UserSuggestion{},
+		&models.SuggestionDismissal{},
+		&models.FollowCooldown{},
+		&models.Mute{},
+		&models.StoryHiddenFrom{},
+		&models.PostLike{},
+		&models.Hashtag{},
+		&models.PostHashtag{},
+		&models.HashtagFollow{},
+		&models.Mention{},
+		&models.SavedPost{},
+		&models.Collection{},
+		&models.CollectionPost{},
+		&models.ExploreCandidate{},
+		&models.FeedItem{},
+		&models.Report{},
+		&models.ModerationAction{},
+		&models.AdminAuditLog{},
+	); err != nil {
 		log.Fatal("AutoMigration failed:", err)
 	}
 
+	setupSearch()
+	if !hadPostMedia {
+		backfillPostMedia()
+	}
+	if !hadTimelines {
+		markExistingContentReadTime()
+	}
+	promoteConfiguredAdmins()
+
 	fmt.Println("✅ Database connection successful & migrated")
 }
+
+// setupSearch enables trigram matching for user search.
+func setupSearch() {
+	stmts := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (lower(username) gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_users_display_name_trgm ON users USING gin (lower(display_name) gin_trgm_ops)",
+	}
+	for _, stmt := range stmts {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Fatal("Search setup failed:", err)
+		}
+	}
+}
+
+// backfillPostMedia gives posts created before carousels their single
+// media item.
+func backfillPostMedia() {
+	if err := DB.Exec(`
+		INSERT INTO post_media (post_id, position, media_url, media_type)
+		SELECT p.id, 0, p.media_url, p.media_type FROM posts p
+		WHERE NOT EXISTS (SELECT 1 FROM post_media m WHERE m.post_id = p.id)`).Error; err != nil {
+		log.Fatal("Post media backfill failed:", err)
+	}
+}
+
+// markExistingContentReadTime keeps content from before fan-out on write
+// visible: feeds read it through follows instead of timelines.
+func markExistingContentReadTime() {
+	for _, table := range []string{"posts", "stories"} {
+		if err := DB.Exec("UPDATE " + table + " SET fanout_on_read = true, fanned_out_at = now() WHERE fanned_out_at IS NULL AND status = 'published'").Error; err != nil {
+			log.Fatal("Timeline setup failed:", err)
+		}
+	}
+}
+
+// promoteConfiguredAdmins bootstraps the first admins from ADMIN_USER_IDS.
+// It does nothing once any admin exists, so roles changed through /admin
+// stick across restarts. Promoted users' old tokens are revoked so the next
+// login carries the new role claim, and each promotion is audited.
+func promoteConfiguredAdmins() {
+	if len(AdminUserIDs) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(AdminUserIDs))
+	for id := range AdminUserIDs {
+		ids = append(ids, id)
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// Replicas starting together must not both bootstrap
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('bootstrap_admins'))").Error; err != nil {
+			return err
+		}
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("role = ?", "admin").Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return nil
+		}
+
+		var users []models.User
+		if err := tx.Select("id", "role").Where("id IN ?", ids).Find(&users).Error; err != nil {
+			return err
+		}
+		for _, u := range users {
+			if err := tx.Model(&u).Updates(map[string]interface{}{
+				"role":          "admin",
+				"token_version": gorm.Expr("token_version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+			id := u.ID
+			// No admin performed this; AdminID 0 is the system
+			if err := tx.Create(&models.AdminAuditLog{
+				AdminID:      0,
+				Action:       "set_role",
+				TargetUserID: &id,
+				TargetType:   "user",
+				TargetID:     &id,
+				OldValue:     u.Role,
+				NewValue:     "admin",
+				Reason:       "bootstrap from ADMIN_USER_IDS",
+			}).Error; err != nil {
+				return err
+			}
+			log.Printf("✅ Promoted user %d to admin", id)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Admin setup failed:", err)
+	}
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const minPasswordLength = 8
+
+// ---------- Change password: PATCH /auth/password ----------
+type changePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ChangePassword checks the current password, stores the new one and revokes
+// every other session. The caller gets a fresh token back.
+func ChangePassword(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req changePasswordReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": fmt.Sprintf("password must be at least %d characters", minPasswordLength)})
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if err := utils.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid credentials"})
+	}
+
+	hashed, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "hash error"})
+	}
+
+	user.Password = hashed
+	user.TokenVersion++ // revokes all previously issued tokens
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"password":      user.Password,
+		"token_version": user.TokenVersion,
+	}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	token, err := utils.GenerateJWT(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "token error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "password changed",
+		"token":   token,
+	})
+}
+
+// ---------- Change email: PATCH /auth/email ----------
+type changeEmailReq struct {
+	NewEmail string `json:"new_email"`
+	Password string `json:"password"`
+}
+
+// ChangeEmail stores the new address as pending and mails a verification
+// link to it. The email only changes once the link is used.
+func ChangeEmail(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req changeEmailReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	req.NewEmail = strings.TrimSpace(strings.ToLower(req.NewEmail))
+	if req.NewEmail == "" || !strings.Contains(req.NewEmail, "@") {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid email"})
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid credentials"})
+	}
+	if req.NewEmail == user.Email {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "email unchanged"})
+	}
+
+	var taken int64
+	if err := config.DB.Model(&models.User{}).Where("email = ?", req.NewEmail).Count(&taken).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if taken > 0 {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "email already in use"})
+	}
+
+	token, err := utils.RandomToken()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "token error"})
+	}
+	hash := utils.HashToken(token)
+	expires := time.Now().Add(config.EmailVerifyTTL)
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"pending_email":        req.NewEmail,
+		"email_verify_hash":    hash,
+		"email_verify_expires": expires,
+	}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	link := fmt.Sprintf("%s/auth/email/verify?token=%s", config.AppURL, token)
+	if err := utils.SendMail(req.NewEmail, "Confirm your new email", "Open this link to confirm your new email:\n"+link); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not send verification email"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "verification email sent"})
+}
+
+// ---------- Verify email: GET /auth/email/verify?token= ----------
+func VerifyEmail(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "missing token"})
+	}
+
+	var user models.User
+	if err := config.DB.Where("email_verify_hash = ? AND email_verify_expires > ?", utils.HashToken(token), time.Now()).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid or expired token"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if user.PendingEmail == nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid or expired token"})
+	}
+
+	// Unique index on email catches anyone who took the address meanwhile
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"email":                *user.PendingEmail,
+		"pending_email":        nil,
+		"email_verify_hash":    nil,
+		"email_verify_expires": nil,
+	}).Error; err != nil {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "email already in use"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "email updated"})
+}
+
+// ---------- Delete account: DELETE /auth/account ----------
+type deleteAccountReq struct {
+	Password string `json:"password"`
+}
+
+// DeleteAccount soft-deletes the account and logs out every session.
+// Logging in again before the grace period ends restores it; afterwards
+// internal.PurgeDeletedAccounts removes the user and their content.
+func DeleteAccount(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req deleteAccountReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid credentials"})
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"deletion_requested_at": now,
+		"token_version":         user.TokenVersion + 1,
+	}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":     "account scheduled for deletion",
+		"purge_after": now.Add(config.AccountDeletionGrace),
+	})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"story-backend/config"
 	"story-backend/models"
@@ -54,7 +55,7 @@ func Signup(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "user exists or bad data"})
 	}
 
-	token, err := utils.GenerateJWT(user.ID)
+	token, err := utils.GenerateJWT(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "token error"})
 	}
@@ -75,7 +76,11 @@ func Login(c echo.Context) error {
 		if err == nil {
 			if userID, err := utils.ExtractUserID(claims); err == nil {
 				var user models.User
-				if err := config.DB.First(&user, userID).Error; err == nil {
+				if err := config.DB.First(&user, userID).Error; err == nil &&
+					user.TokenVersion == utils.ExtractTokenVersion(claims) &&
+					user.DeletionRequestedAt == nil &&
+					user.BannedAt == nil &&
+					(user.SuspendedUntil == nil || !user.SuspendedUntil.After(time.Now())) {
 					return c.JSON(http.StatusOK, echo.Map{
 						"user":  userResponse(user),
 						"token": tokenStr,
@@ -104,7 +109,21 @@ func Login(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid credentials"})
 	}
 
-	token, err := utils.GenerateJWT(user.ID)
+	if user.BannedAt != nil {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "account banned"})
+	}
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "account suspended", "suspended_until": user.SuspendedUntil})
+	}
+
+	// Logging in during the deletion grace period restores the account
+	if user.DeletionRequestedAt != nil {
+		if err := config.DB.Model(&user).Update("deletion_requested_at", nil).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+
+	token, err := utils.GenerateJWT(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "token error"})
 	}
@@ -144,29 +163,31 @@ func ToggleAccountType(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
 	}
 
-	if user.Type == "private" {
-		// Switch to public
-		user.Type = "public"
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if user.Type == "private" {
+			// Switch to public
+			user.Type = "public"
 
-		// Auto-accept all follow requests
-		var requests []models.FollowRequest
-		if err := config.DB.Where("followee_id = ?", user.ID).Find(&requests).Error; err == nil {
-			for _, req := range requests {
-				follow := models.Follow{
-					FollowerID: req.FollowerID,
-					FolloweeID: req.FolloweeID,
+			// Auto-accept all follow requests
+			var followerIDs []uint
+			if err := tx.Model(&models.FollowRequest{}).
+				Where("followee_id = ?", user.ID).
+				Pluck("follower_id", &followerIDs).Error; err != nil {
+				return err
+			}
+			for _, followerID := range followerIDs {
+				if _, err := acceptFollowRequest(tx, followerID, user.ID); err != nil {
+					return err
 				}
-				// ignore if already exists
-				config.DB.FirstOrCreate(&follow, follow)
-				config.DB.Delete(&req) // clean up request
 			}
+		} else {
+			// Switch to private
+			user.Type = "private"
 		}
-	} else {
-		// Switch to private
-		user.Type = "private"
-	}
 
-	if err := config.DB.Save(&user).Error; err != nil {
+		return tx.Model(&user).Update("type", user.Type).Error
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
 	}
 
@@ -179,9 +200,15 @@ func ToggleAccountType(c echo.Context) error {
 // -------------------- Helpers --------------------
 func userResponse(user models.User) echo.Map {
 	return echo.Map{
-		"id":          user.ID,
-		"username":    user.Username,
-		"email":       user.Email,
-		"profile_pic": user.ProfilePic,
+		"id":           user.ID,
+		"username":     user.Username,
+		"email":        user.Email,
+		"profile_pic":  user.ProfilePic,
+		"type":         user.Type,
+		"display_name": user.DisplayName,
+		"bio":          user.Bio,
+		"website":      user.Website,
+		"pronouns":     user.Pronouns,
+		"role":         user.Role,
 	}
 }
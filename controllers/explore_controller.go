@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"story-backend/config"
+	"story-backend/internal"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var errExploreRefreshed = errors.New("explore was refreshed")
+
+// ---------- Explore: GET /explore?cursor=&limit= ----------
+// Recent posts from public accounts I don't follow, served from the pool
+// built by internal.RefreshExploreCandidates. The pool is rebuilt every few
+// minutes with new scores; a cursor from an older pool gets 410 and the
+// client starts over.
+func GetExplore(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+	limit := utils.PageLimit(c.QueryParam("limit"), 24, 50)
+
+	var (
+		version  int64
+		after    bool
+		score    float64
+		afterID  uint
+		pageRows []exploreRow
+	)
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if version, score, afterID, err = utils.DecodePoolCursor(cursor); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		after = true
+	}
+
+	// One snapshot, so the pool version and the rows agree
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var current sql.NullTime
+		if err := tx.Table("explore_candidates").Select("MAX(computed_at)").Scan(&current).Error; err != nil {
+			return err
+		}
+		poolVersion := int64(0)
+		if current.Valid {
+			poolVersion = current.Time.UnixMicro()
+		}
+		if after && version != poolVersion {
+			return errExploreRefreshed
+		}
+		version = poolVersion
+
+		q := tx.Table("explore_candidates AS ec").
+			Select(postRowSelect+", ec.score").
+			Joins("JOIN posts AS p ON p.id = ec.post_id").
+			// The pool can be a few minutes old: re-check the author is still public
+			Joins("JOIN users AS u ON u.id = p.user_id AND u.type = 'public' AND "+internal.ActiveUserSQL("u")).
+			Where("p.status = 'published' AND p.user_id <> ?", userID).
+			Where("NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.followee_id = p.user_id)", userID).
+			Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = ?))", userID, userID).
+			Where("NOT EXISTS (SELECT 1 FROM mutes m WHERE m.muter_id = ? AND m.muted_id = p.user_id AND m.posts)", userID)
+		if after {
+			q = q.Where("(ec.score < ? OR (ec.score = ? AND ec.post_id < ?))", score, score, afterID)
+		}
+		return q.Order("ec.score DESC, ec.post_id DESC").Limit(limit + 1).Scan(&pageRows).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		if errors.Is(err, errExploreRefreshed) {
+			return c.JSON(http.StatusGone, echo.Map{"error": "explore was refreshed, start from the first page"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build explore"})
+	}
+
+	// The keyset position is the lowest-scored row of the page, before the
+	// page is reordered for author diversity
+	var nextCursor *string
+	if len(pageRows) > limit {
+		pageRows = pageRows[:limit]
+		last := pageRows[len(pageRows)-1]
+		cursor := utils.EncodePoolCursor(version, last.Score, last.PostID)
+		nextCursor = &cursor
+	}
+
+	posts := interleaveAuthors(pageRows)
+	if err := attachMedia(posts); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build explore"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"posts":       posts,
+		"next_cursor": nextCursor,
+	})
+}
+
+// -------------------- Helpers --------------------
+
+type exploreRow struct {
+	postRow
+	Score float64 `json:"-"`
+}
+
+// interleaveAuthors orders a page by score while keeping posts by the same
+// author apart: each slot takes the best remaining post whose author differs
+// from the previous slot's, when there is one.
+func interleaveAuthors(rows []exploreRow) []postRow {
+	posts := make([]postRow, 0, len(rows))
+	used := make([]bool, len(rows))
+	var prev uint
+	for len(posts) < len(rows) {
+		pick := -1
+		for i := range rows {
+			if used[i] {
+				continue
+			}
+			if pick == -1 {
+				pick = i // fallback: only this author left
+			}
+			if rows[i].UserID != prev {
+				pick = i
+				break
+			}
+		}
+		used[pick] = true
+		prev = rows[pick].UserID
+		posts = append(posts, rows[pick].postRow)
+	}
+	return posts
+}
@@ -1,14 +1,19 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"story-backend/config"
+	"story-backend/internal"
 	"story-backend/models"
 	"story-backend/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // -------------------- Follow a user --------------------
@@ -19,24 +24,34 @@ func FollowUser(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
 	}
 
-	identifier := c.Param("identifier")
-
 	// Step 1: Find target user (by id or username)
-	var target models.User
-	if id, convErr := strconv.Atoi(identifier); convErr == nil {
-		if err := config.DB.First(&target, id).Error; err != nil {
-			return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
-		}
-	} else {
-		if err := config.DB.Where("username = ?", identifier).First(&target).Error; err != nil {
-			return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
-		}
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
 	}
 
-	// Step 2: Prevent following yourself
+	// Step 2: Prevent following yourself or across a block
 	if target.ID == userID {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "cannot follow yourself"})
 	}
+	blocked, err := isBlockedEitherWay(userID, target.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if blocked {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	// Removed followers may be on a cooldown
+	var cooldowns int64
+	if err := config.DB.Model(&models.FollowCooldown{}).
+		Where("owner_id = ? AND user_id = ? AND until > ?", target.ID, userID, time.Now()).
+		Count(&cooldowns).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if cooldowns > 0 {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "you can't follow this user right now"})
+	}
 
 	// Step 3: If account is private → create follow request
 	if target.Type == "private" {
@@ -55,7 +70,17 @@ func FollowUser(c echo.Context) error {
 		FollowerID: userID,
 		FolloweeID: target.ID,
 	}
-	if err := config.DB.Create(&follow).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if created.Error != nil || created.RowsAffected == 0 {
+			return created.Error
+		}
+		return internal.BackfillTimeline(tx, userID, target.ID)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if follow.ID == 0 {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "already following"})
 	}
 
@@ -69,20 +94,23 @@ func UnfollowUser(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
 	}
 
-	identifier := c.Param("id")
+	target, err := findUserByIdentifier(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
 
-	var target models.User
-	if targetID, err := strconv.Atoi(identifier); err == nil {
-		if err := config.DB.First(&target, targetID).Error; err != nil {
-			return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
-		}
-	} else {
-		if err := config.DB.Where("username = ?", identifier).First(&target).Error; err != nil {
-			return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Follow{}, "follower_id = ? AND followee_id = ?", userID, target.ID).Error; err != nil {
+			return err
 		}
+		return internal.PruneTimeline(tx, userID, target.ID)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
 	}
 
-	if err := config.DB.Delete(&models.Follow{}, "follower_id = ? AND followee_id = ?", userID, target.ID).Error; err != nil {
+	// Also withdraw a pending request, so "unfollow" works on private accounts too
+	if err := config.DB.Delete(&models.FollowRequest{}, "follower_id = ? AND followee_id = ?", userID, target.ID).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
 	}
 
@@ -108,7 +136,7 @@ func GetFollowing(c echo.Context) error {
 		Table("follows").
 		Select("users.id, users.username, users.profile_pic").
 		Joins("JOIN users ON follows.followee_id = users.id").
-		Where("follows.follower_id = ?", userID).
+		Where("follows.follower_id = ? AND "+internal.ActiveUserSQL("users"), userID).
 		Scan(&following).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
 	}
@@ -135,7 +163,7 @@ func GetFollowers(c echo.Context) error {
 		Table("follows").
 		Select("users.id, users.username, users.profile_pic").
 		Joins("JOIN users ON follows.follower_id = users.id").
-		Where("follows.followee_id = ?", userID).
+		Where("follows.followee_id = ? AND "+internal.ActiveUserSQL("users"), userID).
 		Scan(&followers).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
 	}
@@ -167,30 +195,23 @@ func AcceptFollowRequest(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid follower id"})
 	}
 
-	var req models.FollowRequest
-	if err := config.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
-		First(&req).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "follow request not found"})
-	}
-
-	var existingFollow models.Follow
-	if err := config.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
-		First(&existingFollow).Error; err == nil {
-
-		config.DB.Delete(&req)
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "already following"})
+	var status string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		status, err = acceptFollowRequest(tx, uint(followerID), followeeID)
+		return err
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not accept request"})
 	}
 
-	follow := models.Follow{
-		FollowerID: uint(followerID),
-		FolloweeID: followeeID,
-	}
-	if err := config.DB.Create(&follow).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not create follow"})
+	switch status {
+	case requestNotFound:
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "follow request not found"})
+	case requestAlreadyFollowing:
+		// Accepting twice is not an error
+		return c.JSON(http.StatusOK, echo.Map{"message": "already following"})
 	}
-
-	config.DB.Delete(&req)
-
 	return c.JSON(http.StatusOK, echo.Map{"message": "follow request accepted"})
 }
 
@@ -208,17 +229,272 @@ func RejectFollowRequest(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid follower id"})
 	}
 
-	// Find the follow request
-	var req models.FollowRequest
-	if err := config.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
-		First(&req).Error; err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"error": "follow request not found"})
-	}
-
 	// Delete the request (reject)
-	if err := config.DB.Delete(&req).Error; err != nil {
+	status, err := rejectFollowRequest(config.DB, uint(followerID), followeeID)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not reject request"})
 	}
+	switch status {
+	case requestNotFound:
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	case requestNotPending:
+		// Already rejected, cancelled or accepted
+		return c.JSON(http.StatusOK, echo.Map{"message": "no pending request"})
+	}
 
 	return c.JSON(http.StatusOK, echo.Map{"message": "follow request rejected"})
 }
+
+// -------------------- Bulk accept / reject --------------------
+// POST /follow/requests/accept      {"ids": [..]}  accept the listed follower ids
+// POST /follow/requests/accept-all                 accept every pending request
+// POST /follow/requests/reject      {"ids": [..]}  reject the listed follower ids
+// POST /follow/requests/reject-all                 reject every pending request
+// Each call runs in one transaction and reports a status per follower id.
+
+type bulkRequestsReq struct {
+	IDs []uint `json:"ids"`
+}
+
+type bulkRequestResult struct {
+	FollowerID uint   `json:"follower_id"`
+	Status     string `json:"status"` // accepted | rejected | already_following | not_found
+}
+
+func AcceptFollowRequests(c echo.Context) error {
+	return bulkFollowRequests(c, acceptFollowRequest, false)
+}
+
+func AcceptAllFollowRequests(c echo.Context) error {
+	return bulkFollowRequests(c, acceptFollowRequest, true)
+}
+
+func RejectFollowRequests(c echo.Context) error {
+	return bulkFollowRequests(c, rejectFollowRequest, false)
+}
+
+func RejectAllFollowRequests(c echo.Context) error {
+	return bulkFollowRequests(c, rejectFollowRequest, true)
+}
+
+func bulkFollowRequests(c echo.Context, apply func(tx *gorm.DB, followerID, followeeID uint) (string, error), all bool) error {
+	followeeID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var ids []uint
+	if !all {
+		var req bulkRequestsReq
+		if err := c.Bind(&req); err != nil || len(req.IDs) == 0 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "ids required"})
+		}
+		if len(req.IDs) > 500 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "too many ids"})
+		}
+		ids = req.IDs
+	}
+
+	results := []bulkRequestResult{}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if all {
+			if err := tx.Model(&models.FollowRequest{}).
+				Where("followee_id = ?", followeeID).
+				Order("id").
+				Pluck("follower_id", &ids).Error; err != nil {
+				return err
+			}
+		}
+
+		seen := make(map[uint]bool, len(ids))
+		for _, followerID := range ids {
+			if seen[followerID] {
+				continue
+			}
+			seen[followerID] = true
+
+			status, err := apply(tx, followerID, followeeID)
+			if err != nil {
+				return err
+			}
+			results = append(results, bulkRequestResult{FollowerID: followerID, Status: status})
+		}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not process requests"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"results": results})
+}
+
+// -------------------- Request helpers --------------------
+
+const (
+	requestAccepted         = "accepted"
+	requestRejected         = "rejected"
+	requestAlreadyFollowing = "already_following"
+	requestNotPending       = "not_pending"
+	requestNotFound         = "not_found"
+)
+
+// acceptFollowRequest turns a pending request into a follow. Run it inside a
+// transaction so the request is never deleted without the follow existing.
+func acceptFollowRequest(tx *gorm.DB, followerID, followeeID uint) (string, error) {
+	deleted := tx.Delete(&models.FollowRequest{}, "follower_id = ? AND followee_id = ?", followerID, followeeID)
+	if deleted.Error != nil {
+		return "", deleted.Error
+	}
+
+	if deleted.RowsAffected == 0 {
+		// No request: either it was already accepted or it never existed
+		var count int64
+		if err := tx.Model(&models.Follow{}).
+			Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count > 0 {
+			return requestAlreadyFollowing, nil
+		}
+		return requestNotFound, nil
+	}
+
+	follow := models.Follow{FollowerID: followerID, FolloweeID: followeeID}
+	created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+	if created.Error != nil {
+		return "", created.Error
+	}
+	if created.RowsAffected == 0 {
+		return requestAlreadyFollowing, nil
+	}
+	if err := internal.BackfillTimeline(tx, followerID, followeeID); err != nil {
+		return "", err
+	}
+	return requestAccepted, nil
+}
+
+// rejectFollowRequest drops a pending request. Rejecting twice is not an
+// error; only unknown users are reported as not found.
+func rejectFollowRequest(tx *gorm.DB, followerID, followeeID uint) (string, error) {
+	deleted := tx.Delete(&models.FollowRequest{}, "follower_id = ? AND followee_id = ?", followerID, followeeID)
+	if deleted.Error != nil {
+		return "", deleted.Error
+	}
+	if deleted.RowsAffected == 0 {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("id = ?", followerID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return requestNotFound, nil
+		}
+		return requestNotPending, nil
+	}
+	return requestRejected, nil
+}
+
+// -------------------- Sent follow requests --------------------
+// GET /follow/requests/sent: requests I sent that are still pending
+func GetSentFollowRequests(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	type SentRequest struct {
+		ID          uint    `json:"id"` // the followee's user id
+		Username    string  `json:"username"`
+		DisplayName string  `json:"display_name"`
+		ProfilePic  *string `json:"profile_pic"`
+	}
+
+	var requests []SentRequest
+	if err := config.DB.
+		Table("follow_requests").
+		Select("users.id, users.username, users.display_name, users.profile_pic").
+		Joins("JOIN users ON follow_requests.followee_id = users.id").
+		Where("follow_requests.follower_id = ? AND "+internal.ActiveUserSQL("users"), userID).
+		Order("follow_requests.id DESC").
+		Scan(&requests).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"requests": requests})
+}
+
+// DELETE /follow/requests/sent/:id: cancel a request I sent (id = followee id)
+func CancelFollowRequest(c echo.Context) error {
+	followerID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	followeeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || followeeID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"})
+	}
+
+	result := config.DB.Delete(&models.FollowRequest{}, "follower_id = ? AND followee_id = ?", followerID, followeeID)
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not cancel request"})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "follow request not found"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "follow request cancelled"})
+}
+
+// -------------------- Remove a follower --------------------
+// DELETE /follow/followers/:id?block_requests=true
+// Quietly removes someone who follows me (no notification). With
+// block_requests=true they can't follow or request again for a while.
+func RemoveFollower(c echo.Context) error {
+	ownerID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	followerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || followerID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid follower id"})
+	}
+	blockRequests := c.QueryParam("block_requests") == "true"
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Delete(&models.Follow{}, "follower_id = ? AND followee_id = ?", followerID, ownerID)
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := internal.PruneTimeline(tx, uint(followerID), ownerID); err != nil {
+			return err
+		}
+		if !blockRequests {
+			return nil
+		}
+		if err := tx.Delete(&models.FollowRequest{}, "follower_id = ? AND followee_id = ?", followerID, ownerID).Error; err != nil {
+			return err
+		}
+		cooldown := models.FollowCooldown{
+			OwnerID: ownerID,
+			UserID:  uint(followerID),
+			Until:   time.Now().Add(config.FollowerRemovalCooldown),
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "owner_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"until"}),
+		}).Create(&cooldown).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "follower not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not remove follower"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "follower removed"})
+}
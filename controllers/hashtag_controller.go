@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"story-backend/config"
+	"story-backend/internal"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const topTagPosts = 9
+
+// ---------- Hashtag page: GET /tags/:name?cursor=&limit= ----------
+// Top posts (first page only) and recent posts, limited to what the viewer
+// is allowed to see. post_count covers every published post with the tag.
+func GetHashtag(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	tag, err := findHashtag(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "hashtag not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	limit := utils.PageLimit(c.QueryParam("limit"), 20, 50)
+
+	tagged := func() *gorm.DB {
+		return visiblePosts(config.DB.Table("posts AS p").
+			Select(postRowSelect).
+			Joins("JOIN users AS u ON u.id = p.user_id").
+			Joins("JOIN post_hashtags AS ph ON ph.post_id = p.id AND ph.hashtag_id = ?", tag.ID), userID)
+	}
+
+	var postCount int64
+	if err := config.DB.Table("post_hashtags AS ph").
+		Joins("JOIN posts AS p ON p.id = ph.post_id").
+		Joins("JOIN users AS u ON u.id = p.user_id").
+		Where("ph.hashtag_id = ? AND p.status = 'published' AND "+internal.ActiveUserSQL("u"), tag.ID).
+		Count(&postCount).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var following int64
+	if err := config.DB.Model(&models.HashtagFollow{}).
+		Where("user_id = ? AND hashtag_id = ?", userID, tag.ID).
+		Count(&following).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	recentQuery := tagged()
+	cursor := c.QueryParam("cursor")
+	if cursor != "" {
+		micros, id, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		recentQuery = recentQuery.Where("(p.created_at, p.id) < (?, ?)", time.UnixMicro(int64(micros)), id)
+	}
+
+	var recent []postRow
+	if err := recentQuery.Order("p.created_at DESC, p.id DESC").Limit(limit + 1).Scan(&recent).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	var nextCursor *string
+	if len(recent) > limit {
+		recent = recent[:limit]
+		last := recent[len(recent)-1]
+		next := utils.EncodeCursor(float64(last.CreatedAt.UnixMicro()), last.PostID)
+		nextCursor = &next
+	}
+
+	top := []postRow{}
+	if cursor == "" {
+		if err := tagged().Order("like_count DESC, p.created_at DESC").Limit(topTagPosts).Scan(&top).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+
+	if err := attachMedia(recent); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if err := attachMedia(top); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"name":        tag.Name,
+		"post_count":  postCount,
+		"following":   following > 0,
+		"top":         top,
+		"recent":      recent,
+		"next_cursor": nextCursor,
+	})
+}
+
+// ---------- Followed hashtags: GET /tags/following ----------
+func GetFollowedHashtags(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var names []string
+	if err := config.DB.Table("hashtag_follows AS hf").
+		Joins("JOIN hashtags AS h ON h.id = hf.hashtag_id").
+		Where("hf.user_id = ?", userID).
+		Order("hf.created_at DESC").
+		Pluck("h.name", &names).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"hashtags": names})
+}
+
+// ---------- Follow hashtag: POST /tags/:name/follow ----------
+func FollowHashtag(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	tag, err := findHashtag(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "hashtag not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	follow := models.HashtagFollow{UserID: userID, HashtagID: tag.ID}
+	if err := config.DB.Where(follow).FirstOrCreate(&follow).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "following #" + tag.Name})
+}
+
+// ---------- Unfollow hashtag: DELETE /tags/:name/follow ----------
+func UnfollowHashtag(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	tag, err := findHashtag(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "hashtag not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	if err := config.DB.Where("user_id = ? AND hashtag_id = ?", userID, tag.ID).Delete(&models.HashtagFollow{}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "unfollowed #" + tag.Name})
+}
+
+// -------------------- Helpers --------------------
+
+// findHashtag looks a tag up by name, with or without the leading '#'.
+func findHashtag(raw string) (models.Hashtag, error) {
+	var tag models.Hashtag
+	name := utils.NormalizeHashtag(raw)
+	if name == "" {
+		return tag, gorm.ErrRecordNotFound
+	}
+	err := config.DB.First(&tag, "name = ?", name).Error
+	return tag, err
+}
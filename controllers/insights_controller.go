@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var storyEventTypes = map[string]bool{
+	"complete":    true, // watched to the end
+	"exit":        true, // closed the viewer on this story
+	"tap_forward": true,
+	"tap_back":    true,
+}
+
+// ---------- Report viewer event: POST /stories/:id/events ----------
+type storyEventReq struct {
+	Type string `json:"type"`
+}
+
+func RecordStoryEvent(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	storyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid story id"})
+	}
+
+	var req storyEventReq
+	if err := c.Bind(&req); err != nil || !storyEventTypes[req.Type] {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid event type"})
+	}
+
+	// Same visibility rules as ViewStory
+	var story models.Story
+	if err := config.DB.
+		Where("NOT EXISTS (SELECT 1 FROM story_hidden_from AS h WHERE h.owner_id = stories.user_id AND h.viewer_id = ?)", userID).
+		First(&story, "id = ? AND status = ? AND expires_at > ?", storyID, "published", time.Now()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "story not found or expired"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if story.UserID == userID {
+		// Owners watching their own stories would skew the numbers
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	// Events only count from viewers in reach, so rates can't pass 100%
+	var viewed int64
+	if err := config.DB.Model(&models.StoryView{}).
+		Where("story_id = ? AND viewer_id = ?", story.ID, userID).
+		Count(&viewed).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if viewed == 0 {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "story not viewed"})
+	}
+
+	event := models.StoryEvent{StoryID: story.ID, ViewerID: userID, Type: req.Type}
+	if err := config.DB.Create(&event).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ---------- Story insights: GET /stories/:id/insights ----------
+// Reach, hourly views, completion/exit rates, follower share and how the
+// story did within the sequence of stories it was posted with.
+func GetStoryInsights(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	storyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid story id"})
+	}
+
+	var story models.Story
+	if err := config.DB.First(&story, "id = ?", storyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "story not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if story.UserID != userID {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "not your story"})
+	}
+
+	// Reach and follower share
+	var reach struct {
+		Reach     int64
+		Followers int64
+	}
+	if err := config.DB.Raw(`
+		SELECT COUNT(*) AS reach,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM follows f WHERE f.follower_id = sv.viewer_id AND f.followee_id = @owner)) AS followers
+		FROM story_views sv
+		WHERE sv.story_id = @story`,
+		map[string]interface{}{"owner": userID, "story": story.ID}).
+		Scan(&reach).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	// Client-reported events
+	var events struct {
+		Completes   int64
+		Exits       int64
+		TapsForward int64
+		TapsBack    int64
+	}
+	if err := config.DB.Raw(`
+		SELECT
+			COUNT(DISTINCT viewer_id) FILTER (WHERE type = 'complete') AS completes,
+			COUNT(DISTINCT viewer_id) FILTER (WHERE type = 'exit') AS exits,
+			COUNT(*) FILTER (WHERE type = 'tap_forward') AS taps_forward,
+			COUNT(*) FILTER (WHERE type = 'tap_back') AS taps_back
+		FROM story_events
+		WHERE story_id = ?`, story.ID).
+		Scan(&events).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	hourly, err := hourlyViews("story_views.story_id = ?", story.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	// The sequence: my stories that were live at the same time as this one
+	sequence, err := storyStats("s.user_id = ? AND s.status = 'published' AND s.created_at < ? AND s.expires_at > ?",
+		userID, story.ExpiresAt, story.CreatedAt)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"story_id":        story.ID,
+		"reach":           reach.Reach,
+		"follower_share":  rate(reach.Followers, reach.Reach),
+		"completion_rate": rate(events.Completes, reach.Reach),
+		"exit_rate":       rate(events.Exits, reach.Reach),
+		"taps_forward":    events.TapsForward,
+		"taps_back":       events.TapsBack,
+		"hourly_views":    hourly,
+		"sequence":        sequence,
+	})
+}
+
+// ---------- Aggregate insights: GET /insights/stories?days=7|30 ----------
+func GetStoriesInsights(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	days := 7
+	if d := c.QueryParam("days"); d != "" {
+		if days, err = strconv.Atoi(d); err != nil || (days != 7 && days != 30) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "days must be 7 or 30"})
+		}
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	var totals struct {
+		Stories          int64
+		Views            int64
+		AccountsReached  int64
+		FollowerAccounts int64
+		Completes        int64
+		Exits            int64
+	}
+	if err := config.DB.Raw(`
+		WITH mine AS (SELECT id FROM stories WHERE user_id = @owner AND status = 'published' AND created_at >= @since)
+		SELECT
+			(SELECT COUNT(*) FROM mine) AS stories,
+			(SELECT COUNT(*) FROM story_views v WHERE v.story_id IN (SELECT id FROM mine)) AS views,
+			(SELECT COUNT(DISTINCT v.viewer_id) FROM story_views v WHERE v.story_id IN (SELECT id FROM mine)) AS accounts_reached,
+			(SELECT COUNT(DISTINCT v.viewer_id) FROM story_views v
+				JOIN follows f ON f.follower_id = v.viewer_id AND f.followee_id = @owner
+				WHERE v.story_id IN (SELECT id FROM mine)) AS follower_accounts,
+			(SELECT COUNT(*) FROM (SELECT DISTINCT story_id, viewer_id FROM story_events
+				WHERE type = 'complete' AND story_id IN (SELECT id FROM mine)) x) AS completes,
+			(SELECT COUNT(*) FROM (SELECT DISTINCT story_id, viewer_id FROM story_events
+				WHERE type = 'exit' AND story_id IN (SELECT id FROM mine)) x) AS exits`,
+		map[string]interface{}{"owner": userID, "since": since}).
+		Scan(&totals).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	stories, err := storyStats("s.user_id = ? AND s.status = 'published' AND s.created_at >= ?", userID, since)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"days":             days,
+		"stories":          totals.Stories,
+		"views":            totals.Views,
+		"accounts_reached": totals.AccountsReached,
+		"follower_share":   rate(totals.FollowerAccounts, totals.AccountsReached),
+		"completion_rate":  rate(totals.Completes, totals.Views),
+		"exit_rate":        rate(totals.Exits, totals.Views),
+		"per_story":        stories,
+	})
+}
+
+// -------------------- Helpers --------------------
+
+type hourBucket struct {
+	Hour  time.Time `json:"hour"`
+	Views int64     `json:"views"`
+}
+
+func hourlyViews(where string, args ...interface{}) ([]hourBucket, error) {
+	buckets := []hourBucket{}
+	err := config.DB.Table("story_views").
+		Select("date_trunc('hour', story_views.viewed_at) AS hour, COUNT(*) AS views").
+		Where(where, args...).
+		Group("hour").
+		Order("hour").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+type storyStat struct {
+	StoryID        uint      `json:"story_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Reach          int64     `json:"reach"`
+	Completes      int64     `json:"-"`
+	Exits          int64     `json:"-"`
+	CompletionRate float64   `json:"completion_rate"`
+	ExitRate       float64   `json:"exit_rate"`
+	Retention      float64   `json:"retention"` // reach relative to the first story in the list
+}
+
+// storyStats returns per-story reach and event rates for stories matching where (alias s).
+func storyStats(where string, args ...interface{}) ([]storyStat, error) {
+	stats := []storyStat{}
+	err := config.DB.Table("stories AS s").
+		Select(`s.id AS story_id, s.created_at,
+			(SELECT COUNT(*) FROM story_views v WHERE v.story_id = s.id) AS reach,
+			(SELECT COUNT(DISTINCT e.viewer_id) FROM story_events e WHERE e.story_id = s.id AND e.type = 'complete') AS completes,
+			(SELECT COUNT(DISTINCT e.viewer_id) FROM story_events e WHERE e.story_id = s.id AND e.type = 'exit') AS exits`).
+		Where(where, args...).
+		Order("s.created_at ASC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stats {
+		stats[i].CompletionRate = rate(stats[i].Completes, stats[i].Reach)
+		stats[i].ExitRate = rate(stats[i].Exits, stats[i].Reach)
+		stats[i].Retention = rate(stats[i].Reach, stats[0].Reach)
+	}
+	return stats, nil
+}
+
+// rate is part/total, or 0 when there's nothing to divide by.
+func rate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"story-backend/config"
+	"story-backend/internal"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ---------- Update profile: PATCH /me/profile ----------
+// Only fields present in the body are changed.
+type updateProfileReq struct {
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	Website     *string `json:"website"`
+	Pronouns    *string `json:"pronouns"`
+	ProfilePic  *string `json:"profile_pic"`
+}
+
+func UpdateProfile(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req updateProfileReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+
+	updates := map[string]interface{}{}
+	if req.DisplayName != nil {
+		name := strings.TrimSpace(*req.DisplayName)
+		if len(name) > 100 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "display name too long"})
+		}
+		updates["display_name"] = name
+	}
+	if req.Bio != nil {
+		bio := strings.TrimSpace(*req.Bio)
+		if len(bio) > 500 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "bio too long"})
+		}
+		updates["bio"] = bio
+	}
+	if req.Website != nil {
+		website := strings.TrimSpace(*req.Website)
+		if website != "" {
+			u, err := url.ParseRequestURI(website)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid website"})
+			}
+		}
+		updates["website"] = website
+	}
+	if req.Pronouns != nil {
+		pronouns := strings.TrimSpace(*req.Pronouns)
+		if len(pronouns) > 40 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "pronouns too long"})
+		}
+		updates["pronouns"] = pronouns
+	}
+	if req.ProfilePic != nil {
+		updates["profile_pic"] = req.ProfilePic
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"user": userResponse(user)})
+}
+
+// ---------- Settings: GET /me/settings ----------
+func GetSettings(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	return c.JSON(http.StatusOK, settingsResponse(user))
+}
+
+// ---------- Update settings: PATCH /me/settings ----------
+type updateSettingsReq struct {
+	StoryArchiveEnabled *bool   `json:"story_archive_enabled"`
+	MentionPolicy       *string `json:"mention_policy"` // "everyone" | "following" | "nobody"
+}
+
+var mentionPolicies = map[string]bool{"everyone": true, "following": true, "nobody": true}
+
+func UpdateSettings(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req updateSettingsReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+
+	updates := map[string]interface{}{}
+	if req.StoryArchiveEnabled != nil {
+		updates["story_archive_enabled"] = *req.StoryArchiveEnabled
+	}
+	if req.MentionPolicy != nil {
+		if !mentionPolicies[*req.MentionPolicy] {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "mention_policy must be everyone, following or nobody"})
+		}
+		updates["mention_policy"] = *req.MentionPolicy
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, settingsResponse(user))
+}
+
+func settingsResponse(user models.User) echo.Map {
+	return echo.Map{
+		"story_archive_enabled": user.StoryArchiveEnabled,
+		"mention_policy":        user.MentionPolicy,
+	}
+}
+
+// ---------- My mutes: GET /me/mutes ----------
+func GetMutes(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	type mutedUser struct {
+		ID         uint    `json:"id"`
+		Username   string  `json:"username"`
+		ProfilePic *string `json:"profile_pic"`
+		Stories    bool    `json:"stories"`
+		Posts      bool    `json:"posts"`
+	}
+
+	var mutes []mutedUser
+	if err := config.DB.
+		Table("mutes").
+		Select("users.id, users.username, users.profile_pic, mutes.stories, mutes.posts").
+		Joins("JOIN users ON users.id = mutes.muted_id").
+		Where("mutes.muter_id = ?", userID).
+		Order("mutes.created_at DESC").
+		Scan(&mutes).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"mutes": mutes})
+}
+
+// ---------- Mentions of me: GET /me/mentions ----------
+// Posts and stories I'm tagged in, newest first. Mentioned content is
+// visible to me even on private accounts; expired stories are left out.
+func GetMentions(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	type mentionItem struct {
+		MentionID uint          `json:"mention_id"`
+		CreatedAt time.Time     `json:"created_at"`
+		Post      *postRow      `json:"post,omitempty"`
+		Story     *models.Story `json:"story,omitempty"`
+	}
+
+	var mentions []models.Mention
+	if err := config.DB.Where("user_id = ? AND removed_at IS NULL", userID).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = mentions.author_id) OR (b.blocker_id = mentions.author_id AND b.blocked_id = ?))", userID, userID).
+		Order("created_at DESC").
+		Limit(100).
+		Find(&mentions).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var postIDs, storyIDs []uint
+	for _, m := range mentions {
+		if m.PostID != nil {
+			postIDs = append(postIDs, *m.PostID)
+		} else if m.StoryID != nil {
+			storyIDs = append(storyIDs, *m.StoryID)
+		}
+	}
+
+	posts := []postRow{}
+	if len(postIDs) > 0 {
+		if err := visiblePosts(config.DB.Table("posts AS p").
+			Select(postRowSelect).
+			Joins("JOIN users AS u ON u.id = p.user_id"), userID).
+			Where("p.id IN ?", postIDs).
+			Scan(&posts).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+		if err := attachMedia(posts); err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+	var stories []models.Story
+	if len(storyIDs) > 0 {
+		if err := config.DB.
+			Where("id IN ? AND status = ? AND expires_at > ?", storyIDs, "published", time.Now()).
+			Find(&stories).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+
+	postsByID := map[uint]*postRow{}
+	for i := range posts {
+		postsByID[posts[i].PostID] = &posts[i]
+	}
+	storiesByID := map[uint]*models.Story{}
+	for i := range stories {
+		storiesByID[stories[i].ID] = &stories[i]
+	}
+
+	items := []mentionItem{}
+	for _, m := range mentions {
+		item := mentionItem{MentionID: m.ID, CreatedAt: m.CreatedAt}
+		if m.PostID != nil {
+			item.Post = postsByID[*m.PostID]
+		} else if m.StoryID != nil {
+			item.Story = storiesByID[*m.StoryID]
+		}
+		// Skip content that's gone, expired or no longer visible
+		if item.Post == nil && item.Story == nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"mentions": items})
+}
+
+// ---------- Remove myself from a mention: DELETE /me/mentions/:id ----------
+// The caption text is left as is; I'm just no longer tagged or notified.
+func RemoveMention(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid mention id"})
+	}
+
+	result := config.DB.Model(&models.Mention{}).
+		Where("id = ? AND user_id = ? AND removed_at IS NULL", id, userID).
+		Update("removed_at", time.Now())
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "mention not found"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "removed from mention"})
+}
+
+// ---------- Request data export: POST /me/export ----------
+// Starts a background job; the user is notified once it's ready.
+func RequestDataExport(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	// One export at a time
+	var inProgress int64
+	if err := config.DB.Model(&models.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID, []string{"pending", "running"}).
+		Count(&inProgress).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if inProgress > 0 {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "an export is already in progress"})
+	}
+
+	export := models.DataExport{UserID: userID, Status: "pending"}
+	if err := config.DB.Create(&export).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	go internal.RunDataExport(export.ID)
+
+	return c.JSON(http.StatusAccepted, export)
+}
+
+// ---------- Export status: GET /me/export/:id ----------
+// Once the export is ready, each call issues a fresh download link and
+// invalidates the previous one. The link stops working when the export
+// expires.
+func GetDataExport(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid export id"})
+	}
+
+	var export models.DataExport
+	if err := config.DB.First(&export, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "export not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	if export.Status != "ready" || export.ExpiresAt == nil || !export.ExpiresAt.After(time.Now()) {
+		return c.JSON(http.StatusOK, echo.Map{"export": export})
+	}
+
+	token, err := utils.RandomToken()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "token error"})
+	}
+	if err := config.DB.Model(&export).Update("token_hash", utils.HashToken(token)).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"export":       export,
+		"download_url": fmt.Sprintf("%s/exports/%s", config.AppURL, token),
+	})
+}
+
+// ---------- Download export: GET /exports/:token ----------
+// Public: the unguessable token from GET /me/export/:id is the credential.
+func DownloadDataExport(c echo.Context) error {
+	hash := utils.HashToken(c.Param("token"))
+
+	var export models.DataExport
+	if err := config.DB.First(&export, "token_hash = ? AND status = ? AND expires_at > ?", hash, "ready", time.Now()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "link invalid or expired"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.Attachment(export.FilePath, "my-data.zip")
+}
+
+// ---------- Notifications: GET /me/notifications ----------
+func GetNotifications(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var notifications []models.Notification
+	if err := config.DB.Where("user_id = ?", userID).
+		Order("created_at desc").
+		Limit(100).
+		Find(&notifications).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"notifications": notifications})
+}
+
+// ---------- Mark notifications read: PATCH /me/notifications/read ----------
+func MarkNotificationsRead(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	if err := config.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "notifications marked read"})
+}
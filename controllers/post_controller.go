@@ -1,70 +1,210 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"story-backend/config"
+	"story-backend/internal"
 	"story-backend/models"
 	"story-backend/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
-// ---------- Feed: GET /posts/feed ----------
+// ---------- Feed: GET /posts/feed?mode=ranked ----------
+// Newest first by default. See getRankedFeed for ?mode=ranked.
 func GetPostsFeed(c echo.Context) error {
 	userID, err := utils.GetUserID(c)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
 	}
 
-	type row struct {
-		PostID     uint      `json:"post_id"`
-		UserID     uint      `json:"user_id"`
-		Username   string    `json:"username"`
-		ProfilePic string    `json:"profile_pic"`
-		Caption    string    `json:"caption"`
-		MediaURL   string    `json:"media_url"`
-		MediaType  string    `json:"media_type"`
-		CreatedAt  time.Time `json:"created_at"`
+	if c.QueryParam("mode") == "ranked" {
+		return getRankedFeed(c, userID)
 	}
 
-	var rows []row
-	err = config.DB.
-		Table("posts AS p").
-		Select(`
-			p.id AS post_id,
-			p.user_id,
-			u.username,
-			u.profile_pic,
-			p.caption,
-			p.media_url,
-			p.media_type,
-			p.created_at`).
-		Joins("JOIN users AS u ON u.id = p.user_id").
-		Joins("LEFT JOIN follows AS f ON f.followee_id = p.user_id AND f.follower_id = ?", userID).
-		Where("(f.follower_id IS NOT NULL OR p.user_id = ?)", userID).
+	var rows []postRow
+	err = homeFeed(userID).
+		Select(postRowSelect).
 		Order("p.created_at DESC").
 		Scan(&rows).Error
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build feed"})
 	}
+	if err := attachMedia(rows); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build feed"})
+	}
 
-	return c.JSON(http.StatusOK, rows)
+	// Same shape as the ranked feed; there's no caught-up marker here
+	return c.JSON(http.StatusOK, echo.Map{
+		"mode":            "chronological",
+		"posts":           rows,
+		"caught_up_index": nil,
+	})
+}
+
+const (
+	rankedFeedWindow = 3 * 24 * time.Hour // posts older than this aren't ranked
+	rankedFeedMax    = 500
+	rankedFeedOlder  = 100 // chronological posts after the ranked ones
+)
+
+// getRankedFeed orders recent posts with internal.DefaultRanker, and
+// posts new since my last visit come before the ones I've already had a
+// chance to see. caught_up_index is where the client shows "You're all
+// caught up". Older posts follow in chronological order.
+//
+// Loading the feed doesn't count as a visit: the client reports reaching
+// the marker with as_of (see MarkFeedCaughtUp), so a quick refresh keeps
+// unseen posts above it.
+func getRankedFeed(c echo.Context, userID uint) error {
+	var me models.User
+	if err := config.DB.Select("id", "feed_visited_at").First(&me, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-rankedFeedWindow)
+	lastVisit := windowStart
+	if me.FeedVisitedAt != nil && me.FeedVisitedAt.After(windowStart) {
+		lastVisit = *me.FeedVisitedAt
+	}
+	closenessSince := now.AddDate(0, 0, -30)
+
+	type rankedRow struct {
+		postRow
+		AuthorStoryViews int64
+		AuthorLikes      int64
+	}
+	var recent []rankedRow
+	if err := homeFeed(userID).
+		Select(postRowSelect+`,
+			(SELECT COUNT(*) FROM story_views sv JOIN stories s ON s.id = sv.story_id
+				WHERE sv.viewer_id = ? AND s.user_id = p.user_id AND sv.viewed_at > ?) AS author_story_views,
+			(SELECT COUNT(*) FROM post_likes pl JOIN posts lp ON lp.id = pl.post_id
+				WHERE pl.user_id = ? AND lp.user_id = p.user_id AND pl.created_at > ?) AS author_likes`,
+			userID, closenessSince, userID, closenessSince).
+		Where("p.created_at > ?", windowStart).
+		Order("p.created_at DESC").
+		Limit(rankedFeedMax).
+		Scan(&recent).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build feed"})
+	}
+
+	var older []postRow
+	if err := homeFeed(userID).
+		Select(postRowSelect).
+		Where("p.created_at <= ?", windowStart).
+		Order("p.created_at DESC").
+		Limit(rankedFeedOlder).
+		Scan(&older).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build feed"})
+	}
+
+	byID := make(map[uint]postRow, len(recent))
+	var fresh, seen []internal.FeedCandidate
+	for _, r := range recent {
+		byID[r.PostID] = r.postRow
+		candidate := internal.FeedCandidate{
+			PostID:           r.PostID,
+			AuthorID:         r.UserID,
+			CreatedAt:        r.CreatedAt,
+			LikeCount:        r.LikeCount,
+			AuthorStoryViews: r.AuthorStoryViews,
+			AuthorLikes:      r.AuthorLikes,
+		}
+		if r.CreatedAt.After(lastVisit) {
+			fresh = append(fresh, candidate)
+		} else {
+			seen = append(seen, candidate)
+		}
+	}
+
+	ranker := internal.NewDefaultRanker()
+	posts := make([]postRow, 0, len(recent)+len(older))
+	for _, cand := range internal.RankFeed(ranker, fresh, now) {
+		posts = append(posts, byID[cand.PostID])
+	}
+	caughtUpIndex := len(posts)
+	for _, cand := range internal.RankFeed(ranker, seen, now) {
+		posts = append(posts, byID[cand.PostID])
+	}
+	posts = append(posts, older...)
+
+	if err := attachMedia(posts); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build feed"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"mode":            "ranked",
+		"posts":           posts,
+		"caught_up_index": caughtUpIndex,
+		"as_of":           now,
+	})
+}
+
+// ---------- Caught up: POST /posts/feed/caught-up ----------
+// Body {"as_of": "<as_of from the ranked feed>"}. Sent once the client has
+// shown caught_up_index; posts up to as_of then count as seen.
+type feedCaughtUpReq struct {
+	AsOf *time.Time `json:"as_of"`
+}
+
+func MarkFeedCaughtUp(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	var req feedCaughtUpReq
+	if err := c.Bind(&req); err != nil || req.AsOf == nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "as_of required"})
+	}
+	asOf := *req.AsOf
+	if now := time.Now(); asOf.After(now) {
+		asOf = now
+	}
+
+	// Only ever moves forward, so an old tab can't resurface seen posts
+	if err := config.DB.Model(&models.User{}).
+		Where("id = ? AND (feed_visited_at IS NULL OR feed_visited_at < ?)", userID, asOf).
+		Update("feed_visited_at", asOf).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
 
 // ---------- User Posts: GET /posts/user/:id ----------
+// Only the posts I may see: none from private accounts I don't follow or
+// from anyone I've blocked or who blocked me.
 func GetUserPosts(c echo.Context) error {
+	viewerID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
 	targetIDParam := c.Param("id")
 	targetID, err := strconv.Atoi(targetIDParam)
 	if err != nil || targetID <= 0 {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"})
 	}
 
-	var rows []models.Post
-	if err := config.DB.Where("user_id = ?", targetID).Order("created_at DESC").Find(&rows).Error; err != nil {
+	rows := []postRow{}
+	if err := visiblePosts(config.DB.Table("posts AS p").
+		Select(postRowSelect).
+		Joins("JOIN users AS u ON u.id = p.user_id"), viewerID).
+		Where("p.user_id = ?", targetID).
+		Order("p.created_at DESC").
+		Scan(&rows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to fetch posts"})
+	}
+	if err := attachMedia(rows); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to fetch posts"})
 	}
 
@@ -72,10 +212,25 @@ func GetUserPosts(c echo.Context) error {
 }
 
 // ---------- Add Post: POST /posts/add ----------
-type addPostReq struct {
-	Caption   string `json:"caption"`
+// "media" holds 1 to maxPostMedia items in display order. Older clients can
+// still send a single media_url/media_type instead.
+const maxPostMedia = 10
+
+type postMediaReq struct {
 	MediaURL  string `json:"media_url"`
 	MediaType string `json:"media_type"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	AltText   string `json:"alt_text"`
+}
+
+type addPostReq struct {
+	Caption   string         `json:"caption"`
+	MediaURL  string         `json:"media_url"`
+	MediaType string         `json:"media_type"`
+	Media     []postMediaReq `json:"media"`
+	PublishAt *time.Time     `json:"publish_at"` // future time schedules the post
+	Draft     bool           `json:"draft"`
 }
 
 func AddPost(c echo.Context) error {
@@ -85,25 +240,165 @@ func AddPost(c echo.Context) error {
 	}
 
 	var req addPostReq
-	if err := c.Bind(&req); err != nil || req.MediaURL == "" || req.MediaType == "" {
+	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
 	}
+	if len(req.Media) == 0 && req.MediaURL != "" {
+		req.Media = []postMediaReq{{MediaURL: req.MediaURL, MediaType: req.MediaType}}
+	}
+	if len(req.Media) == 0 || len(req.Media) > maxPostMedia {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "a post needs 1 to 10 media items"})
+	}
+
+	media := make([]models.PostMedia, len(req.Media))
+	for i, m := range req.Media {
+		// Any media_type older clients send is still accepted
+		if m.MediaURL == "" || m.MediaType == "" || len(m.MediaType) > 20 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "each media item needs a media_url and a media_type"})
+		}
+		if m.Width < 0 || m.Height < 0 || len(m.AltText) > 1000 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid media item"})
+		}
+		media[i] = models.PostMedia{
+			Position:  i,
+			MediaURL:  m.MediaURL,
+			MediaType: m.MediaType,
+			Width:     m.Width,
+			Height:    m.Height,
+			AltText:   m.AltText,
+		}
+	}
 
+	now := time.Now()
 	post := models.Post{
 		UserID:    userID,
 		Caption:   req.Caption,
-		MediaURL:  req.MediaURL,
-		MediaType: req.MediaType,
-		CreatedAt: time.Now(),
+		MediaURL:  media[0].MediaURL,
+		MediaType: media[0].MediaType,
+		CreatedAt: now,
+		Status:    publishState(req.Draft, req.PublishAt, now),
+		PublishAt: req.PublishAt,
+		Media:     media,
 	}
 
-	if err := config.DB.Create(&post).Error; err != nil {
+	// Creates the post_media rows along with the post
+	var mentioned []models.Mention
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		mentioned, err = syncCaption(tx, post)
+		return err
+	}); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to create post"})
 	}
+	if post.Status == "published" {
+		internal.NotifyMentions(mentioned)
+		go internal.FanOutPost(post.ID) // fast path; ProcessFanOut catches anything missed
+	}
 
 	return c.JSON(http.StatusCreated, post)
 }
 
+// ---------- Edit Post: PATCH /posts/:id ----------
+type editPostReq struct {
+	Caption *string `json:"caption"`
+}
+
+func EditPost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid post id"})
+	}
+
+	var post models.Post
+	if err := config.DB.Preload("Media", internal.OrderedMedia).
+		First(&post, "id = ? AND user_id = ?", postID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusForbidden, echo.Map{"error": "post not found or not yours"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var req editPostReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	if req.Caption != nil {
+		post.Caption = *req.Caption
+		var mentioned []models.Mention
+		if err := config.DB.Transaction(func(tx *gorm.DB) error {
+			// Not Model(&post): it has Media loaded, which GORM would upsert
+			if err := tx.Model(&models.Post{}).Where("id = ?", post.ID).Update("caption", post.Caption).Error; err != nil {
+				return err
+			}
+			mentioned, err = syncCaption(tx, post)
+			return err
+		}); err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to update post"})
+		}
+		if post.Status == "published" {
+			internal.NotifyMentions(mentioned)
+		}
+	}
+
+	return c.JSON(http.StatusOK, post)
+}
+
+// ---------- Like: POST /posts/:id/like ----------
+func LikePost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid post id"})
+	}
+
+	var visible int64
+	if err := visiblePosts(config.DB.Table("posts AS p").Joins("JOIN users AS u ON u.id = p.user_id"), userID).
+		Where("p.id = ?", postID).
+		Count(&visible).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if visible == 0 {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "post not found"})
+	}
+
+	like := models.PostLike{PostID: uint(postID), UserID: userID}
+	if err := config.DB.Where(like).FirstOrCreate(&like).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "liked"})
+}
+
+// ---------- Unlike: DELETE /posts/:id/like ----------
+func UnlikePost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid post id"})
+	}
+
+	if err := config.DB.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&models.PostLike{}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "unliked"})
+}
+
 // ---------- Delete Post: DELETE /posts/:id ----------
 func DeletePost(c echo.Context) error {
 	userID, err := utils.GetUserID(c)
@@ -117,9 +412,122 @@ func DeletePost(c echo.Context) error {
 		return c.JSON(http.StatusForbidden, echo.Map{"error": "post not found or not yours"})
 	}
 
-	if err := config.DB.Delete(&post).Error; err != nil {
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return internal.DeletePostRows(tx, []uint{post.ID})
+	}); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to delete post"})
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{"message": "deleted"})
 }
+
+// -------------------- Helpers --------------------
+
+// postRow is the shape of a post in feeds and listings.
+type postRow struct {
+	PostID     uint               `json:"post_id"`
+	UserID     uint               `json:"user_id"`
+	Username   string             `json:"username"`
+	ProfilePic string             `json:"profile_pic"`
+	Caption    string             `json:"caption"`
+	MediaURL   string             `json:"media_url"` // first item, for older clients
+	MediaType  string             `json:"media_type"`
+	LikeCount  int64              `json:"like_count"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Media      []models.PostMedia `json:"media" gorm:"-"`
+}
+
+// postRowSelect fills a postRow from "posts AS p JOIN users AS u".
+const postRowSelect = `
+	p.id AS post_id,
+	p.user_id,
+	u.username,
+	u.profile_pic,
+	p.caption,
+	p.media_url,
+	p.media_type,
+	(SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS like_count,
+	p.created_at`
+
+// visiblePosts limits a query over "posts AS p JOIN users AS u" to published
+// posts the viewer may see: public or followed authors or posts mentioning
+// the viewer, with no blocks either way.
+func visiblePosts(db *gorm.DB, viewerID uint) *gorm.DB {
+	return db.
+		Where("p.status = 'published' AND "+internal.ActiveUserSQL("u")).
+		Where(`(u.type = 'public' OR p.user_id = @me
+			OR EXISTS (SELECT 1 FROM follows vf WHERE vf.follower_id = @me AND vf.followee_id = p.user_id)
+			OR EXISTS (SELECT 1 FROM mentions vm WHERE vm.post_id = p.id AND vm.user_id = @me AND vm.removed_at IS NULL))`,
+			map[string]interface{}{"me": viewerID}).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = @me AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = @me))",
+			map[string]interface{}{"me": viewerID})
+}
+
+// syncCaption updates the hashtags and mentions parsed from a post's caption.
+// It returns newly mentioned users, to notify once the post is published.
+func syncCaption(tx *gorm.DB, post models.Post) ([]models.Mention, error) {
+	if err := internal.SyncPostHashtags(tx, post.ID, post.Caption); err != nil {
+		return nil, err
+	}
+	return internal.SyncMentions(tx, "post_id", post.ID, post.UserID, utils.ParseMentions(post.Caption))
+}
+
+// homeFeed selects the posts for my home feed from "posts AS p JOIN users
+// AS u": my timeline (fanned out on write), my own posts, posts of big
+// accounts I follow (read at request time), and public posts under hashtags
+// I follow.
+func homeFeed(userID uint) *gorm.DB {
+	return config.DB.
+		Table("posts AS p").
+		Joins("JOIN users AS u ON u.id = p.user_id").
+		Where(`p.id IN (
+			SELECT fi.post_id FROM feed_items fi WHERE fi.user_id = @me AND fi.post_id IS NOT NULL
+			UNION ALL
+			SELECT own.id FROM posts own WHERE own.user_id = @me
+			UNION ALL
+			SELECT rp.id FROM posts rp JOIN follows f ON f.followee_id = rp.user_id AND f.follower_id = @me
+				WHERE rp.fanout_on_read
+			UNION ALL
+			SELECT ph.post_id FROM post_hashtags ph JOIN hashtag_follows hf ON hf.hashtag_id = ph.hashtag_id
+				WHERE hf.user_id = @me)`, map[string]interface{}{"me": userID}).
+		// Hashtag posts only from public accounts unless I follow the author
+		Where(`(u.type = 'public' OR p.user_id = ? OR EXISTS (SELECT 1 FROM follows vf WHERE vf.follower_id = ? AND vf.followee_id = p.user_id))`, userID, userID).
+		Where("p.status = ?", "published").
+		Where(internal.ActiveUserSQL("u")).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = ?))", userID, userID).
+		// Leave out authors whose posts I muted
+		Where("NOT EXISTS (SELECT 1 FROM mutes AS m WHERE m.muter_id = ? AND m.muted_id = p.user_id AND m.posts)", userID)
+}
+
+// attachMedia fills in the carousel items of each row.
+func attachMedia(rows []postRow) error {
+	ids := make([]uint, len(rows))
+	for i, r := range rows {
+		ids[i] = r.PostID
+	}
+	media, err := mediaByPost(ids)
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		rows[i].Media = media[rows[i].PostID]
+	}
+	return nil
+}
+
+// mediaByPost loads the carousel items of the given posts, in order.
+func mediaByPost(postIDs []uint) (map[uint][]models.PostMedia, error) {
+	byPost := map[uint][]models.PostMedia{}
+	if len(postIDs) == 0 {
+		return byPost, nil
+	}
+
+	var media []models.PostMedia
+	if err := config.DB.Where("post_id IN ?", postIDs).Order("post_id, position").Find(&media).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range media {
+		byPost[m.PostID] = append(byPost[m.PostID], m)
+	}
+	return byPost, nil
+}
@@ -0,0 +1,411 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"story-backend/config"
+	"story-backend/internal"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate_speech":    true,
+	"nudity":         true,
+	"violence":       true,
+	"self_harm":      true,
+	"misinformation": true,
+	"impersonation":  true,
+	"ip_violation":   true,
+	"other":          true,
+}
+
+// ---------- Report: POST /reports ----------
+// Body {"target_type": "user|story|post|comment", "target_id": 1, "reason": "spam", "notes": "..."}
+type reportReq struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	Reason     string `json:"reason"`
+	Notes      string `json:"notes"`
+}
+
+func CreateReport(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req reportReq
+	if err := c.Bind(&req); err != nil || req.TargetID == 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	if !reportReasons[req.Reason] {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid reason"})
+	}
+	req.Notes = strings.TrimSpace(req.Notes)
+	if len(req.Notes) > 2000 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "notes too long"})
+	}
+
+	ownerID, snapshot, err := reportSnapshot(userID, req.TargetType, req.TargetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errUnknownTarget), errors.Is(err, errCommentReports):
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.JSON(http.StatusNotFound, echo.Map{"error": req.TargetType + " not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if ownerID == userID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "cannot report yourself"})
+	}
+
+	// One open report per reporter and target
+	var open int64
+	if err := config.DB.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", userID, req.TargetType, req.TargetID, "open").
+		Count(&open).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if open > 0 {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "you already reported this"})
+	}
+
+	report := models.Report{
+		ReporterID:   userID,
+		TargetType:   req.TargetType,
+		TargetID:     req.TargetID,
+		TargetUserID: ownerID,
+		Reason:       req.Reason,
+		Notes:        req.Notes,
+		Snapshot:     snapshot,
+		Status:       "open",
+	}
+	if err := config.DB.Create(&report).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"message": "report received", "id": report.ID})
+}
+
+// ---------- Moderation queue: GET /moderation/reports?status=open&target_type=&cursor=&limit= ----------
+// Oldest first, so nothing waits forever.
+func GetReports(c echo.Context) error {
+	limit := utils.PageLimit(c.QueryParam("limit"), 50, 100)
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = "open"
+	}
+	q := config.DB.Model(&models.Report{}).Where("status = ?", status)
+	if t := c.QueryParam("target_type"); t != "" {
+		q = q.Where("target_type = ?", t)
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		_, after, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		q = q.Where("id > ?", after)
+	}
+
+	type reportRow struct {
+		models.Report
+		TargetReports int64 `json:"target_reports"` // open reports on the same target
+	}
+	var rows []reportRow
+	if err := q.Select(`reports.*,
+		(SELECT COUNT(*) FROM reports o WHERE o.target_type = reports.target_type
+			AND o.target_id = reports.target_id AND o.status = 'open') AS target_reports`).
+		Order("id").
+		Limit(limit + 1).
+		Scan(&rows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var nextCursor *string
+	if len(rows) > limit {
+		rows = rows[:limit]
+		cursor := utils.EncodeCursor(0, rows[len(rows)-1].ID)
+		nextCursor = &cursor
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"reports": rows, "next_cursor": nextCursor})
+}
+
+// ---------- Report detail: GET /moderation/reports/:id ----------
+func GetReport(c echo.Context) error {
+	report, err := findReport(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "report not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var actions []models.ModerationAction
+	if err := config.DB.Where("report_id = ?", report.ID).Order("created_at").Find(&actions).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	// Earlier actions against the same account help decide what to do
+	var history []models.ModerationAction
+	if err := config.DB.Where("target_user_id = ?", report.TargetUserID).
+		Order("created_at desc").
+		Limit(20).
+		Find(&history).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"report":       report,
+		"actions":      actions,
+		"user_history": history,
+	})
+}
+
+// ---------- Act on a report: POST /moderation/reports/:id/actions ----------
+// Body {"action": "remove_content|warn|suspend|dismiss", "notes": "...", "days": 7}
+// Every action is written to the audit trail and resolves the report.
+type moderationActionReq struct {
+	Action string `json:"action"`
+	Notes  string `json:"notes"`
+	Days   int    `json:"days"` // suspension length, default 7
+}
+
+func ActOnReport(c echo.Context) error {
+	moderatorID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	report, err := findReport(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "report not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var req moderationActionReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+
+	now := time.Now()
+	action := models.ModerationAction{
+		ModeratorID:  moderatorID,
+		ReportID:     &report.ID,
+		Action:       req.Action,
+		TargetUserID: report.TargetUserID,
+		TargetType:   report.TargetType,
+		TargetID:     &report.TargetID,
+		Notes:        strings.TrimSpace(req.Notes),
+	}
+	status := "actioned"
+
+	switch req.Action {
+	case "remove_content":
+		if report.TargetType != "post" && report.TargetType != "story" {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "only posts and stories can be removed"})
+		}
+	case "warn":
+	case "suspend":
+		if req.Days == 0 {
+			req.Days = 7
+		}
+		if req.Days < 1 || req.Days > 365 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "days must be between 1 and 365"})
+		}
+		var target models.User
+		if err := config.DB.Select("id", "role").First(&target, report.TargetUserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+			}
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+		if msg := restrictTargetError(moderatorID, utils.GetRole(c), target); msg != "" {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": msg})
+		}
+		until := now.AddDate(0, 0, req.Days)
+		action.Until = &until
+	case "dismiss":
+		status = "dismissed"
+	default:
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "action must be remove_content, warn, suspend or dismiss"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the report so a repeated or concurrent action can't apply twice
+		var locked models.Report
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, report.ID).Error; err != nil {
+			return err
+		}
+		if locked.Status != "open" {
+			return errReportResolved
+		}
+
+		switch req.Action {
+		case "remove_content":
+			var err error
+			if report.TargetType == "post" {
+				err = internal.DeletePostRows(tx, []uint{report.TargetID})
+			} else {
+				err = internal.DeleteStoryRows(tx, []uint{report.TargetID})
+			}
+			if err != nil {
+				return err
+			}
+		case "suspend":
+			if err := internal.SuspendUser(tx, report.TargetUserID, *action.Until); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&action).Error; err != nil {
+			return err
+		}
+
+		// Removing content settles every open report about it
+		resolve := tx.Model(&models.Report{}).Where("id = ?", report.ID)
+		if req.Action == "remove_content" {
+			resolve = tx.Model(&models.Report{}).
+				Where("id = ? OR (target_type = ? AND target_id = ? AND status = 'open')", report.ID, report.TargetType, report.TargetID)
+		}
+		return resolve.Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_by": moderatorID,
+			"resolved_at": now,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, errReportResolved) {
+			return c.JSON(http.StatusConflict, echo.Map{"error": "report already resolved"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "could not apply action"})
+	}
+
+	if msg := moderationNotice(action); msg != "" {
+		internal.Notify(models.Notification{
+			UserID:     report.TargetUserID,
+			Type:       "moderation_" + action.Action,
+			EntityType: report.TargetType,
+			EntityID:   &report.TargetID,
+			Message:    msg,
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"action": action, "status": status})
+}
+
+// ---------- Audit trail: GET /moderation/actions?user_id=&moderator_id= ----------
+func GetModerationActions(c echo.Context) error {
+	q := config.DB.Model(&models.ModerationAction{})
+	if id := c.QueryParam("user_id"); id != "" {
+		q = q.Where("target_user_id = ?", id)
+	}
+	if id := c.QueryParam("moderator_id"); id != "" {
+		q = q.Where("moderator_id = ?", id)
+	}
+
+	var actions []models.ModerationAction
+	if err := q.Order("created_at desc").
+		Limit(utils.PageLimit(c.QueryParam("limit"), 100, 500)).
+		Find(&actions).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"actions": actions})
+}
+
+// -------------------- Helpers --------------------
+
+var (
+	errUnknownTarget  = errors.New("target_type must be user, story or post")
+	errCommentReports = errors.New("comments can't be reported yet")
+	errReportResolved = errors.New("report already resolved")
+)
+
+// reportSnapshot returns the owner of the reported content and a JSON copy
+// of it. Reporters can only report what they're able to see.
+func reportSnapshot(reporterID uint, targetType string, targetID uint) (uint, string, error) {
+	var owner uint
+	var content interface{}
+
+	switch targetType {
+	case "user":
+		var user models.User
+		if err := config.DB.First(&user, "id = ? AND deletion_requested_at IS NULL", targetID).Error; err != nil {
+			return 0, "", err
+		}
+		owner, content = user.ID, profileResponse(user)
+
+	case "post":
+		var visible int64
+		if err := visiblePosts(config.DB.Table("posts AS p").Joins("JOIN users AS u ON u.id = p.user_id"), reporterID).
+			Where("p.id = ?", targetID).
+			Count(&visible).Error; err != nil {
+			return 0, "", err
+		}
+		if visible == 0 {
+			return 0, "", gorm.ErrRecordNotFound
+		}
+		var post models.Post
+		if err := config.DB.Preload("Media", internal.OrderedMedia).First(&post, targetID).Error; err != nil {
+			return 0, "", err
+		}
+		owner, content = post.UserID, post
+
+	case "story":
+		var story models.Story
+		if err := config.DB.
+			Where("NOT EXISTS (SELECT 1 FROM story_hidden_from AS h WHERE h.owner_id = stories.user_id AND h.viewer_id = ?)", reporterID).
+			First(&story, "id = ? AND status = ?", targetID, "published").Error; err != nil {
+			return 0, "", err
+		}
+		owner, content = story.UserID, story
+
+	case "comment":
+		// Comments don't exist yet; accept the type once they do
+		return 0, "", errCommentReports
+
+	default:
+		return 0, "", errUnknownTarget
+	}
+
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return 0, "", err
+	}
+	return owner, string(raw), nil
+}
+
+func findReport(rawID string) (models.Report, error) {
+	var report models.Report
+	err := config.DB.First(&report, "id = ?", rawID).Error
+	return report, err
+}
+
+// moderationNotice is what the affected user is told, if anything.
+func moderationNotice(action models.ModerationAction) string {
+	switch action.Action {
+	case "remove_content":
+		return "Your " + action.TargetType + " was removed for going against our community guidelines."
+	case "warn":
+		return "You received a warning for going against our community guidelines."
+	case "suspend":
+		return "Your account is suspended until " + action.Until.Format("Jan 2, 2006") + "."
+	}
+	return ""
+}
@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"story-backend/config"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ---------- Save post: POST /posts/:id/save ----------
+// Optional body {"collection_id": 3} also files the post in a collection.
+type savePostReq struct {
+	CollectionID *uint `json:"collection_id"`
+}
+
+func SavePost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid post id"})
+	}
+
+	var req savePostReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+
+	var visible int64
+	if err := visiblePosts(config.DB.Table("posts AS p").Joins("JOIN users AS u ON u.id = p.user_id"), userID).
+		Where("p.id = ?", postID).
+		Count(&visible).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if visible == 0 {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "post not found"})
+	}
+
+	if req.CollectionID != nil {
+		if _, err := findCollection(userID, *req.CollectionID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.JSON(http.StatusNotFound, echo.Map{"error": "collection not found"})
+			}
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		saved := models.SavedPost{UserID: userID, PostID: uint(postID)}
+		if err := tx.Where(saved).FirstOrCreate(&saved).Error; err != nil {
+			return err
+		}
+		if req.CollectionID == nil {
+			return nil
+		}
+		item := models.CollectionPost{CollectionID: *req.CollectionID, PostID: uint(postID)}
+		return tx.Where(item).FirstOrCreate(&item).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "saved"})
+}
+
+// ---------- Unsave post: DELETE /posts/:id/save ----------
+// Also takes the post out of all my collections.
+func UnsavePost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid post id"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		mine := tx.Model(&models.Collection{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("post_id = ? AND collection_id IN (?)", postID, mine).Delete(&models.CollectionPost{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.SavedPost{}).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "unsaved"})
+}
+
+// ---------- Saved posts: GET /me/saved?cursor=&limit= ----------
+func GetSavedPosts(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	q := config.DB.Table("saved_posts AS sp").
+		Joins("JOIN posts AS p ON p.id = sp.post_id").
+		Where("sp.user_id = ?", userID)
+	var after uint
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if _, after, err = utils.DecodeCursor(cursor); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+	}
+	posts, nextCursor, err := savedPostsPage(q, userID, after, utils.PageLimit(c.QueryParam("limit"), 24, 50))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"posts": posts, "next_cursor": nextCursor})
+}
+
+// ---------- Collections: GET /me/collections ----------
+func GetCollections(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	type collectionRow struct {
+		ID        uint   `json:"id"`
+		Name      string `json:"name"`
+		PostCount int64  `json:"post_count"`
+	}
+
+	var rows []collectionRow
+	if err := config.DB.Table("collections AS col").
+		Select("col.id, col.name, (SELECT COUNT(*) FROM collection_posts cp WHERE cp.collection_id = col.id) AS post_count").
+		Where("col.user_id = ?", userID).
+		Order("col.created_at DESC").
+		Scan(&rows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"collections": rows})
+}
+
+// ---------- Create collection: POST /me/collections ----------
+type collectionReq struct {
+	Name string `json:"name"`
+}
+
+func CreateCollection(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req collectionReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	name, ok := collectionName(req.Name)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "name must be 1 to 100 characters"})
+	}
+
+	var taken int64
+	if err := config.DB.Model(&models.Collection{}).Where("user_id = ? AND name = ?", userID, name).Count(&taken).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if taken > 0 {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "you already have a collection with that name"})
+	}
+
+	collection := models.Collection{UserID: userID, Name: name}
+	if err := config.DB.Create(&collection).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusCreated, collection)
+}
+
+// ---------- Collection posts: GET /me/collections/:id?cursor=&limit= ----------
+func GetCollection(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid collection id"})
+	}
+	collection, err := findCollection(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "collection not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	// Everything in a collection is also saved, so this pages like GET /me/saved
+	q := config.DB.Table("collection_posts AS cp").
+		Joins("JOIN saved_posts AS sp ON sp.post_id = cp.post_id AND sp.user_id = ?", userID).
+		Joins("JOIN posts AS p ON p.id = cp.post_id").
+		Where("cp.collection_id = ?", collection.ID)
+	var after uint
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if _, after, err = utils.DecodeCursor(cursor); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+	}
+	posts, nextCursor, err := savedPostsPage(q, userID, after, utils.PageLimit(c.QueryParam("limit"), 24, 50))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"collection": collection, "posts": posts, "next_cursor": nextCursor})
+}
+
+// ---------- Rename collection: PATCH /me/collections/:id ----------
+func RenameCollection(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid collection id"})
+	}
+
+	var req collectionReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	name, ok := collectionName(req.Name)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "name must be 1 to 100 characters"})
+	}
+
+	collection, err := findCollection(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "collection not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var taken int64
+	if err := config.DB.Model(&models.Collection{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, collection.ID).
+		Count(&taken).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if taken > 0 {
+		return c.JSON(http.StatusConflict, echo.Map{"error": "you already have a collection with that name"})
+	}
+
+	if err := config.DB.Model(&collection).Update("name", name).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, collection)
+}
+
+// ---------- Delete collection: DELETE /me/collections/:id ----------
+// The posts stay saved.
+func DeleteCollection(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid collection id"})
+	}
+
+	collection, err := findCollection(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "collection not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionPost{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&collection).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "collection deleted"})
+}
+
+// ---------- Remove from collection: DELETE /me/collections/:id/posts/:post_id ----------
+// The post stays saved.
+func RemoveFromCollection(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid collection id"})
+	}
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil || postID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid post id"})
+	}
+
+	if _, err := findCollection(userID, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "collection not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	if err := config.DB.Where("collection_id = ? AND post_id = ?", id, postID).Delete(&models.CollectionPost{}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "removed from collection"})
+}
+
+// -------------------- Helpers --------------------
+
+func findCollection(userID, id uint) (models.Collection, error) {
+	var collection models.Collection
+	err := config.DB.First(&collection, "id = ? AND user_id = ?", id, userID).Error
+	return collection, err
+}
+
+func collectionName(raw string) (string, bool) {
+	name := strings.TrimSpace(raw)
+	return name, name != "" && len(name) <= 100
+}
+
+// savedPostsPage pages through saved posts, newest save first, starting
+// below saved id after (0 for the first page). q selects from
+// "saved_posts AS sp" joined to "posts AS p". Posts that are no longer
+// visible (deleted, blocked, or a private account I stopped following) are
+// left out.
+func savedPostsPage(q *gorm.DB, userID, after uint, limit int) ([]postRow, *string, error) {
+	q = visiblePosts(q.Joins("JOIN users AS u ON u.id = p.user_id"), userID).
+		Select(postRowSelect + ", sp.id AS saved_id")
+	if after > 0 {
+		q = q.Where("sp.id < ?", after)
+	}
+
+	type savedRow struct {
+		postRow
+		SavedID uint
+	}
+	var rows []savedRow
+	if err := q.Order("sp.id DESC").Limit(limit + 1).Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var nextCursor *string
+	if len(rows) > limit {
+		rows = rows[:limit]
+		next := utils.EncodeCursor(0, rows[len(rows)-1].SavedID)
+		nextCursor = &next
+	}
+
+	posts := make([]postRow, len(rows))
+	for i, r := range rows {
+		posts[i] = r.postRow
+	}
+	if err := attachMedia(posts); err != nil {
+		return nil, nil, err
+	}
+	return posts, nextCursor, nil
+}
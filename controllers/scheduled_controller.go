@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"story-backend/config"
+	"story-backend/internal"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Drafts and scheduled items are only visible to their owner through these
+// endpoints. internal.PublishScheduledContent flips scheduled items live.
+
+// ---------- Scheduled stories: GET /stories/scheduled ----------
+func GetScheduledStories(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var stories []models.Story
+	if err := config.DB.Where("user_id = ? AND status IN ?", userID, []string{"draft", "scheduled"}).
+		Order("publish_at asc nulls last, created_at desc").
+		Find(&stories).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
+	}
+
+	return c.JSON(http.StatusOK, stories)
+}
+
+// ---------- Edit scheduled story: PATCH /stories/scheduled/:id ----------
+type updateScheduledStoryReq struct {
+	MediaURL   *string    `json:"media_url"`
+	MediaType  *string    `json:"media_type"`
+	TTLMinutes *int       `json:"ttl_minutes"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Draft      *bool      `json:"draft"`
+	Mentions   *[]string  `json:"mentions"` // replaces the mention list when present
+}
+
+func UpdateScheduledStory(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var story models.Story
+	if err := findScheduled(c, userID, &story); err != nil {
+		return err
+	}
+
+	var req updateScheduledStoryReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	if req.MediaURL != nil && *req.MediaURL != "" {
+		story.MediaURL = *req.MediaURL
+	}
+	if req.MediaType != nil && *req.MediaType != "" {
+		story.MediaType = *req.MediaType
+	}
+	if req.TTLMinutes != nil && *req.TTLMinutes > 0 && *req.TTLMinutes <= 1440 {
+		story.TTLMinutes = *req.TTLMinutes
+	}
+
+	status, publishAt, ok := rescheduleState(story.Status == "draft", story.PublishAt, req.Draft, req.PublishAt)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "publish_at required to schedule"})
+	}
+	story.Status, story.PublishAt = status, publishAt
+	if publishAt != nil {
+		story.ExpiresAt = publishAt.Add(time.Duration(story.TTLMinutes) * time.Minute)
+	}
+
+	if req.Mentions != nil && len(*req.Mentions) > utils.MaxMentionsPerItem {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "too many mentions"})
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&story).Error; err != nil {
+			return err
+		}
+		if req.Mentions == nil {
+			return nil
+		}
+		// Mentioned users hear about it when the story is published
+		_, err := internal.SyncMentions(tx, "story_id", story.ID, userID, *req.Mentions)
+		return err
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
+	}
+	return c.JSON(http.StatusOK, story)
+}
+
+// ---------- Cancel scheduled story: DELETE /stories/scheduled/:id ----------
+func CancelScheduledStory(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var story models.Story
+	if err := findScheduled(c, userID, &story); err != nil {
+		return err
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return internal.DeleteStoryRows(tx, []uint{story.ID})
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "delete failed"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ---------- Scheduled posts: GET /posts/scheduled ----------
+func GetScheduledPosts(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	var posts []models.Post
+	if err := config.DB.Preload("Media", internal.OrderedMedia).Where("user_id = ? AND status IN ?", userID, []string{"draft", "scheduled"}).
+		Order("publish_at asc nulls last, created_at desc").
+		Find(&posts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to fetch posts"})
+	}
+
+	return c.JSON(http.StatusOK, posts)
+}
+
+// ---------- Edit scheduled post: PATCH /posts/scheduled/:id ----------
+type updateScheduledPostReq struct {
+	Caption   *string    `json:"caption"`
+	PublishAt *time.Time `json:"publish_at"`
+	Draft     *bool      `json:"draft"`
+}
+
+func UpdateScheduledPost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	var post models.Post
+	if err := findScheduled(c, userID, &post); err != nil {
+		return err
+	}
+
+	var req updateScheduledPostReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	if req.Caption != nil {
+		post.Caption = *req.Caption
+	}
+
+	status, publishAt, ok := rescheduleState(post.Status == "draft", post.PublishAt, req.Draft, req.PublishAt)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "publish_at required to schedule"})
+	}
+	post.Status, post.PublishAt = status, publishAt
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&post).Error; err != nil {
+			return err
+		}
+		_, err := syncCaption(tx, post)
+		return err
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to update post"})
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+// ---------- Cancel scheduled post: DELETE /posts/scheduled/:id ----------
+func CancelScheduledPost(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
+	}
+
+	var post models.Post
+	if err := findScheduled(c, userID, &post); err != nil {
+		return err
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return internal.DeletePostRows(tx, []uint{post.ID})
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to delete post"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// -------------------- Helpers --------------------
+
+// publishState decides the initial status of new content.
+func publishState(draft bool, publishAt *time.Time, now time.Time) string {
+	switch {
+	case draft:
+		return "draft"
+	case publishAt != nil && publishAt.After(now):
+		return "scheduled"
+	default:
+		return "published"
+	}
+}
+
+// rescheduleState applies a PATCH to a draft/scheduled item. A scheduled
+// item always needs a publish time; a past one is published on the next
+// publisher run.
+func rescheduleState(isDraft bool, current *time.Time, draft *bool, publishAt *time.Time) (string, *time.Time, bool) {
+	if draft != nil {
+		isDraft = *draft
+	}
+	if publishAt != nil {
+		current = publishAt
+	}
+	if isDraft {
+		return "draft", current, true
+	}
+	if current == nil {
+		return "", nil, false
+	}
+	return "scheduled", current, true
+}
+
+// findScheduled loads the caller's draft or scheduled story/post from :id,
+// writing the error response itself when it fails.
+func findScheduled(c echo.Context, userID uint, dest interface{}) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid id"})
+	}
+
+	if err := config.DB.First(dest, "id = ? AND user_id = ? AND status IN ?", id, userID, []string{"draft", "scheduled"}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "scheduled item not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	return nil
+}
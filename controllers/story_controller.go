@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"story-backend/config"
+	"story-backend/internal"
 	"story-backend/models"
 	"story-backend/utils"
 
@@ -15,7 +16,10 @@ import (
 )
 
 // ---------- Feed: GET /stories/feed ----------
-// Returns stories from users the logged-in user follows
+// One block per user: my own first, then users with unseen stories by
+// affinity (how much I watched their stories and liked their posts
+// lately), then users I've fully seen, then muted users. Stories inside a
+// block are oldest first and start_index points at the first unseen one.
 func GetStoriesFeed(c echo.Context) error {
 	userID, err := utils.GetUserID(c)
 	if err != nil {
@@ -31,31 +35,51 @@ func GetStoriesFeed(c echo.Context) error {
 		MediaURL   string
 		MediaType  string
 		CreatedAt  time.Time
-		ViewedID   *uint
+		Seen       bool
+		Muted      bool
 	}
 
 	var rows []row
-	err = config.DB.
-		Table("stories AS s").
-		Select(`
-			u.id AS user_id,
-			u.username,
-			u.profile_pic,
-			s.id AS story_id,
-			s.media_url,
-			s.media_type,
-			s.created_at,
-			sv.id AS viewed_id`).
-		// Join users table
-		Joins("JOIN users AS u ON u.id = s.user_id").
-		// Left join follows so we can filter by either follow relationship OR own stories
-		Joins("LEFT JOIN follows AS f ON f.followee_id = s.user_id AND f.follower_id = ?", userID).
-		// Keep only stories that belong to someone I follow OR myself
-		Where("(f.follower_id IS NOT NULL OR s.user_id = ?)", userID).
-		Where("s.expires_at > ?", time.Now()).
-		// Check if current user has viewed story
-		Joins("LEFT JOIN story_views AS sv ON sv.story_id = s.id AND sv.viewer_id = ?", userID).
-		Order("u.id ASC, s.created_at ASC").
+	err = config.DB.Raw(`
+		WITH feed AS (
+			SELECT s.user_id, u.username, u.profile_pic,
+				s.id AS story_id, s.media_url, s.media_type, s.created_at,
+				(sv.id IS NOT NULL) AS seen,
+				COALESCE(m.stories, false) AS muted
+			FROM stories s
+			JOIN users u ON u.id = s.user_id
+			-- Check if current user has viewed story
+			LEFT JOIN story_views sv ON sv.story_id = s.id AND sv.viewer_id = @me
+			LEFT JOIN mutes m ON m.muter_id = @me AND m.muted_id = s.user_id
+			-- My timeline (fanned out on write), my own stories, and stories of
+			-- big accounts I follow (read at request time)
+			WHERE s.id IN (
+				SELECT fi.story_id FROM feed_items fi WHERE fi.user_id = @me AND fi.story_id IS NOT NULL
+				UNION ALL
+				SELECT own.id FROM stories own WHERE own.user_id = @me
+				UNION ALL
+				SELECT rs.id FROM stories rs JOIN follows f ON f.followee_id = rs.user_id AND f.follower_id = @me
+					WHERE rs.fanout_on_read)
+				AND s.status = 'published' AND s.expires_at > @now AND `+internal.ActiveUserSQL("u")+`
+				-- Skip owners who hid their stories from me
+				AND NOT EXISTS (SELECT 1 FROM story_hidden_from h WHERE h.owner_id = s.user_id AND h.viewer_id = @me)
+		),
+		rings AS (
+			SELECT f.user_id,
+				bool_and(f.seen) AS all_seen,
+				max(f.created_at) AS latest,
+				(SELECT COUNT(*) FROM story_views av JOIN stories ast ON ast.id = av.story_id
+					WHERE av.viewer_id = @me AND ast.user_id = f.user_id AND av.viewed_at > @since)
+				+ 2 * (SELECT COUNT(*) FROM post_likes al JOIN posts ap ON ap.id = al.post_id
+					WHERE al.user_id = @me AND ap.user_id = f.user_id AND al.created_at > @since) AS affinity
+			FROM feed f
+			GROUP BY f.user_id
+		)
+		SELECT f.* FROM feed f
+		JOIN rings b ON b.user_id = f.user_id
+		ORDER BY (f.user_id = @me) DESC, f.muted ASC, b.all_seen ASC, b.affinity DESC, b.latest DESC,
+			f.user_id ASC, f.created_at ASC`,
+		map[string]interface{}{"me": userID, "now": time.Now(), "since": time.Now().AddDate(0, 0, -30)}).
 		Scan(&rows).Error
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "failed to build feed"})
@@ -74,7 +98,9 @@ func GetStoriesFeed(c echo.Context) error {
 		Username   string      `json:"username"`
 		ProfilePic string      `json:"profile_pic"`
 		Stories    []storyItem `json:"stories"`
+		StartIndex int         `json:"start_index"` // first unseen story, 0 when all are seen
 		AllSeen    bool        `json:"all_seen"`
+		Muted      bool        `json:"muted"`
 	}
 
 	feedMap := make(map[uint]*userBlock)
@@ -87,19 +113,20 @@ func GetStoriesFeed(c echo.Context) error {
 				ProfilePic: r.ProfilePic,
 				Stories:    []storyItem{},
 				AllSeen:    true,
+				Muted:      r.Muted,
 			}
 			feedMap[r.UserID] = block
 		}
-		seen := r.ViewedID != nil
-		if !seen {
+		if !r.Seen && block.AllSeen {
 			block.AllSeen = false
+			block.StartIndex = len(block.Stories)
 		}
 		block.Stories = append(block.Stories, storyItem{
 			ID:        r.StoryID,
 			MediaURL:  r.MediaURL,
 			MediaType: r.MediaType,
 			CreatedAt: r.CreatedAt,
-			Seen:      seen,
+			Seen:      r.Seen,
 		})
 	}
 
@@ -118,16 +145,21 @@ func GetStoriesFeed(c echo.Context) error {
 
 // ---------- Get user stories: GET /stories/user/:id ----------
 func GetUserStories(c echo.Context) error {
+	viewerID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
 	uidStr := c.Param("id")
 	targetID, err := strconv.Atoi(uidStr)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"})
 	}
 
-	// Check user exists
+	// Check user exists and I may see their stories
 	var count int64
-	if err := config.DB.Model(&models.User{}).
-		Where("id = ?", targetID).
+	if err := storyOwnersVisibleTo(config.DB.Table("users AS u"), viewerID).
+		Where("u.id = ?", targetID).
 		Count(&count).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
 	}
@@ -135,8 +167,19 @@ func GetUserStories(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
 	}
 
+	// Hidden viewers just see no stories
+	var hidden int64
+	if err := config.DB.Model(&models.StoryHiddenFrom{}).
+		Where("owner_id = ? AND viewer_id = ?", targetID, viewerID).
+		Count(&hidden).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
+	}
+	if hidden > 0 {
+		return c.JSON(http.StatusOK, []models.Story{})
+	}
+
 	var stories []models.Story
-	if err := config.DB.Where("user_id = ? AND expires_at > ?", targetID, time.Now()).
+	if err := config.DB.Where("user_id = ? AND status = ? AND expires_at > ?", targetID, "published", time.Now()).
 		Order("created_at desc").
 		Find(&stories).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
@@ -146,10 +189,16 @@ func GetUserStories(c echo.Context) error {
 }
 
 // ---------- Add story: POST /stories ----------
+// With "draft": true or a future "publish_at" the story is held back and
+// published later by internal.PublishScheduledContent.
+// "mentions" lists usernames tagged on the story.
 type addStoryReq struct {
-	MediaURL   string `json:"media_url"`
-	MediaType  string `json:"media_type"`
-	TTLMinutes int    `json:"ttl_minutes"`
+	MediaURL   string     `json:"media_url"`
+	MediaType  string     `json:"media_type"`
+	TTLMinutes int        `json:"ttl_minutes"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Draft      bool       `json:"draft"`
+	Mentions   []string   `json:"mentions"`
 }
 
 func AddStory(c echo.Context) error {
@@ -162,6 +211,9 @@ func AddStory(c echo.Context) error {
 	if err := c.Bind(&req); err != nil || req.MediaURL == "" || req.MediaType == "" {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
 	}
+	if len(req.Mentions) > utils.MaxMentionsPerItem {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "too many mentions"})
+	}
 
 	// TTL (default 24h, max 24h)
 	if req.TTLMinutes <= 0 || req.TTLMinutes > 1440 {
@@ -169,21 +221,47 @@ func AddStory(c echo.Context) error {
 	}
 	ttl := time.Duration(req.TTLMinutes) * time.Minute
 
+	now := time.Now()
+	status := publishState(req.Draft, req.PublishAt, now)
+
+	// Expiry counts from the publish time; the publisher recomputes it when
+	// the story actually goes live
+	start := now
+	if status == "scheduled" {
+		start = *req.PublishAt
+	}
+
 	story := models.Story{
-		UserID:    userID,
-		MediaURL:  req.MediaURL,
-		MediaType: req.MediaType,
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(ttl),
+		UserID:     userID,
+		MediaURL:   req.MediaURL,
+		MediaType:  req.MediaType,
+		CreatedAt:  now,
+		ExpiresAt:  start.Add(ttl),
+		Status:     status,
+		PublishAt:  req.PublishAt,
+		TTLMinutes: req.TTLMinutes,
 	}
 
-	if err := config.DB.Create(&story).Error; err != nil {
+	var mentioned []models.Mention
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&story).Error; err != nil {
+			return err
+		}
+		mentioned, err = internal.SyncMentions(tx, "story_id", story.ID, userID, req.Mentions)
+		return err
+	}); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
 	}
+	if story.Status == "published" {
+		internal.NotifyMentions(mentioned)
+		go internal.FanOutStory(story.ID) // fast path; ProcessFanOut catches anything missed
+	}
 	return c.JSON(http.StatusCreated, story)
 }
 
-// ---------- Delete story: DELETE /stories/:id ----------
+// ---------- Delete story: DELETE /stories/:id?permanent=true ----------
+// A live story goes to the archive when the owner has archiving on.
+// Archived stories (or ?permanent=true) are deleted for good.
 func DeleteStory(c echo.Context) error {
 	userID, err := utils.GetUserID(c)
 	if err != nil {
@@ -204,13 +282,129 @@ func DeleteStory(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
 	}
 
-	if err := config.DB.Delete(&story).Error; err != nil {
+	// Drafts and scheduled stories were never seen, so they're just deleted
+	if story.Status == "published" && story.ArchivedAt == nil && c.QueryParam("permanent") != "true" {
+		var owner models.User
+		if err := config.DB.Select("story_archive_enabled").First(&owner, userID).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+		if owner.StoryArchiveEnabled {
+			now := time.Now()
+			if err := config.DB.Model(&story).Updates(map[string]interface{}{
+				"archived_at": now,
+				"expires_at":  now, // out of the feed right away
+			}).Error; err != nil {
+				return c.JSON(http.StatusInternalServerError, echo.Map{"error": "archive failed"})
+			}
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return internal.DeleteStoryRows(tx, []uint{story.ID})
+	}); err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "delete failed"})
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// ---------- Archive: GET /stories/archive?from=YYYY-MM-DD&to=YYYY-MM-DD&tz=&cursor=&limit= ----------
+// Only ever shows the logged-in user's own archive. Days are in tz (an IANA
+// name, default UTC).
+func GetStoryArchive(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+	limit := utils.PageLimit(c.QueryParam("limit"), 50, 200)
+
+	loc, err := queryLocation(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid tz"})
+	}
+
+	q := config.DB.Where("user_id = ? AND archived_at IS NOT NULL", userID)
+	if from := c.QueryParam("from"); from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid from date"})
+		}
+		q = q.Where("created_at >= ?", day)
+	}
+	if to := c.QueryParam("to"); to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid to date"})
+		}
+		q = q.Where("created_at < ?", day.AddDate(0, 0, 1)) // inclusive
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		micros, id, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		q = q.Where("(created_at, id) < (?, ?)", time.UnixMicro(int64(micros)), id)
+	}
+
+	var stories []models.Story
+	if err := q.Order("created_at desc, id desc").Limit(limit + 1).Find(&stories).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
+	}
+
+	var nextCursor *string
+	if len(stories) > limit {
+		stories = stories[:limit]
+		last := stories[len(stories)-1]
+		next := utils.EncodeCursor(float64(last.CreatedAt.UnixMicro()), last.ID)
+		nextCursor = &next
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"stories": stories, "next_cursor": nextCursor})
+}
+
+// ---------- Archive calendar: GET /stories/archive/calendar?month=YYYY-MM&tz= ----------
+// Number of archived stories per day in tz (default UTC), for a calendar view.
+func GetStoryArchiveCalendar(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	loc, err := queryLocation(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid tz"})
+	}
+
+	month := time.Now().In(loc)
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, loc)
+	if m := c.QueryParam("month"); m != "" {
+		if month, err = time.ParseInLocation("2006-01", m, loc); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid month"})
+		}
+	}
+
+	days := []struct {
+		Day   string `json:"day"`
+		Count int64  `json:"count"`
+	}{}
+	if err := config.DB.Model(&models.Story{}).
+		Select("to_char(created_at AT TIME ZONE ?, 'YYYY-MM-DD') AS day, COUNT(*) AS count", loc.String()).
+		Where("user_id = ? AND archived_at IS NOT NULL", userID).
+		Where("created_at >= ? AND created_at < ?", month, month.AddDate(0, 1, 0)).
+		Group("day").
+		Order("day").
+		Scan(&days).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "database error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"month": month.Format("2006-01"),
+		"tz":    loc.String(),
+		"days":  days,
+	})
+}
+
 // ---------- Mark story as viewed: POST /stories/:id/view ----------
 func ViewStory(c echo.Context) error {
 	userID, err := utils.GetUserID(c)
@@ -224,9 +418,11 @@ func ViewStory(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid story id"})
 	}
 
-	// Ensure story exists and is active
+	// Ensure story exists, is active and I may see it
 	var story models.Story
-	if err := config.DB.First(&story, "id = ? AND expires_at > ?", storyID, time.Now()).Error; err != nil {
+	if err := storyOwnersVisibleTo(config.DB.Joins("JOIN users AS u ON u.id = stories.user_id"), userID).
+		Where("NOT EXISTS (SELECT 1 FROM story_hidden_from AS h WHERE h.owner_id = stories.user_id AND h.viewer_id = ?)", userID).
+		First(&story, "stories.id = ? AND stories.status = ? AND stories.expires_at > ?", storyID, "published", time.Now()).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, echo.Map{"error": "story not found or expired"})
 		}
@@ -278,9 +474,11 @@ func GetStoryViews(c echo.Context) error {
 		Username   string    `json:"username"`
 		ProfilePic *string   `json:"profile_pic"`
 		ViewedAt   time.Time `json:"viewed_at"`
+		Hidden     bool      `json:"hidden"` // toggle with POST/DELETE /stories/hidden/:viewer_id
 	}
 	if err := config.DB.Table("story_views").
-		Select("story_views.id, story_views.viewer_id, users.username, users.profile_pic, story_views.viewed_at").
+		Select(`story_views.id, story_views.viewer_id, users.username, users.profile_pic, story_views.viewed_at,
+			EXISTS (SELECT 1 FROM story_hidden_from AS h WHERE h.owner_id = ? AND h.viewer_id = story_views.viewer_id) AS hidden`, userID).
 		Joins("JOIN users ON users.id = story_views.viewer_id").
 		Where("story_views.story_id = ?", storyID).
 		Order("story_views.viewed_at desc").
@@ -297,3 +495,121 @@ func GetStoryViews(c echo.Context) error {
 		"views":       views,
 	})
 }
+
+// ---------- Hidden-from list: GET /stories/hidden ----------
+// Viewers who can't see any of my stories
+func GetStoryHiddenFrom(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var viewers []struct {
+		ID         uint    `json:"id"`
+		Username   string  `json:"username"`
+		ProfilePic *string `json:"profile_pic"`
+	}
+	if err := config.DB.Table("story_hidden_from").
+		Select("users.id, users.username, users.profile_pic").
+		Joins("JOIN users ON users.id = story_hidden_from.viewer_id").
+		Where("story_hidden_from.owner_id = ?", userID).
+		Order("story_hidden_from.created_at desc").
+		Find(&viewers).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"hidden_from": viewers})
+}
+
+// ---------- Hide stories from a viewer: POST /stories/hidden/:id ----------
+func HideStoriesFrom(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	viewerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || viewerID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"})
+	}
+	if uint(viewerID) == userID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "cannot hide stories from yourself"})
+	}
+
+	var viewer models.User
+	if err := config.DB.First(&viewer, viewerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		hidden := models.StoryHiddenFrom{OwnerID: userID, ViewerID: viewer.ID}
+		if err := tx.Where(hidden).FirstOrCreate(&hidden).Error; err != nil {
+			return err
+		}
+		return internal.PruneStoryTimeline(tx, viewer.ID, userID)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "stories hidden"})
+}
+
+// ---------- Unhide stories: DELETE /stories/hidden/:id ----------
+func UnhideStoriesFrom(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	viewerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || viewerID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.StoryHiddenFrom{}, "owner_id = ? AND viewer_id = ?", userID, viewerID).Error; err != nil {
+			return err
+		}
+		// Followers get the live stories back in their timeline
+		var following int64
+		if err := tx.Model(&models.Follow{}).Where("follower_id = ? AND followee_id = ?", viewerID, userID).Count(&following).Error; err != nil {
+			return err
+		}
+		if following == 0 {
+			return nil
+		}
+		return internal.BackfillTimeline(tx, uint(viewerID), userID)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "stories unhidden"})
+}
+
+// -------------------- Helpers --------------------
+
+// queryLocation reads the tz query param (an IANA name like "Europe/Berlin").
+func queryLocation(c echo.Context) (*time.Location, error) {
+	tz := c.QueryParam("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// storyOwnersVisibleTo limits "users AS u" to accounts whose stories the
+// viewer may see: active, not blocked either way, and public, followed or
+// the viewer themselves.
+func storyOwnersVisibleTo(db *gorm.DB, viewerID uint) *gorm.DB {
+	return db.
+		Where(internal.ActiveUserSQL("u")).
+		Where("(u.type = 'public' OR u.id = @me OR EXISTS (SELECT 1 FROM follows vf WHERE vf.follower_id = @me AND vf.followee_id = u.id))",
+			map[string]interface{}{"me": viewerID}).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = @me AND b.blocked_id = u.id) OR (b.blocker_id = u.id AND b.blocked_id = @me))",
+			map[string]interface{}{"me": viewerID})
+}
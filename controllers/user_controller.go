@@ -0,0 +1,585 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"story-backend/config"
+	"story-backend/internal"
+	"story-backend/models"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ---------- Get profile: GET /users/:identifier ----------
+// identifier is a user id or a username (same as FollowUser)
+func GetUserProfile(c echo.Context) error {
+	viewerID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	rel, err := relationshipBetween(viewerID, target.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	// Someone who blocked you doesn't exist as far as you're concerned
+	if rel.BlockedBy {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	type counts struct {
+		Followers int64 `json:"followers"`
+		Following int64 `json:"following"`
+		Posts     int64 `json:"posts"`
+	}
+	var cnt counts
+	if err := config.DB.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM follows WHERE followee_id = @id) AS followers,
+			(SELECT COUNT(*) FROM follows WHERE follower_id = @id) AS following,
+			(SELECT COUNT(*) FROM posts WHERE user_id = @id AND status = 'published') AS posts`,
+		map[string]interface{}{"id": target.ID}).
+		Scan(&cnt).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	resp := echo.Map{
+		"user":   profileResponse(target),
+		"counts": cnt,
+	}
+	if target.ID != viewerID {
+		resp["relationship"] = rel
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ---------- Mutuals: GET /users/:identifier/mutuals?limit= ----------
+// Accounts I follow that also follow the target ("followed by alice, bob and 12 others").
+func GetMutuals(c echo.Context) error {
+	viewerID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if target.ID == viewerID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "no mutuals with yourself"})
+	}
+
+	rel, err := relationshipBetween(viewerID, target.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if rel.BlockedBy || rel.Blocked {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	// A private account's followers are only visible to its followers
+	if target.Type == "private" && !rel.Following {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "this account is private"})
+	}
+
+	limit := utils.PageLimit(c.QueryParam("limit"), 3, 50)
+
+	type mutual struct {
+		ID          uint    `json:"id"`
+		Username    string  `json:"username"`
+		DisplayName string  `json:"display_name"`
+		ProfilePic  *string `json:"profile_pic"`
+		Total       int64   `json:"-"`
+	}
+
+	// One pass over both follow edges; the window count gives the total before LIMIT.
+	// People whose stories I watch most come first, then most recently followed.
+	var mutuals []mutual
+	if err := config.DB.Raw(`
+		SELECT u.id, u.username, u.display_name, u.profile_pic, COUNT(*) OVER () AS total
+		FROM follows mine
+		JOIN follows theirs ON theirs.follower_id = mine.followee_id AND theirs.followee_id = @target
+		JOIN users u ON u.id = mine.followee_id AND `+internal.ActiveUserSQL("u")+`
+		WHERE mine.follower_id = @me
+		ORDER BY (
+			SELECT COUNT(*) FROM story_views sv JOIN stories s ON s.id = sv.story_id
+			WHERE sv.viewer_id = @me AND s.user_id = u.id
+		) DESC, mine.id DESC
+		LIMIT @limit`,
+		map[string]interface{}{"me": viewerID, "target": target.ID, "limit": limit}).
+		Scan(&mutuals).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	var total int64
+	names := make([]string, 0, len(mutuals))
+	for _, m := range mutuals {
+		total = m.Total
+		names = append(names, m.Username)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"total":   total,
+		"mutuals": mutuals,
+		"summary": followedBySummary(names, total),
+	})
+}
+
+// ---------- Search: GET /users/search?q=&cursor=&limit= ----------
+// Prefix + typo-tolerant (pg_trgm) match on username and display name.
+// Mutual follows and accounts whose stories you watch rank higher.
+func SearchUsers(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	q := strings.ToLower(strings.TrimSpace(c.QueryParam("q")))
+	if q == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "missing query"})
+	}
+	limit := utils.PageLimit(c.QueryParam("limit"), 20, 50)
+
+	params := map[string]interface{}{
+		"me":     userID,
+		"q":      q,
+		"prefix": escapeLike(q) + "%",
+		"since":  time.Now().AddDate(0, 0, -30),
+		"limit":  limit + 1,
+	}
+	keyset := ""
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		score, id, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		params["cscore"], params["cid"] = score, id
+		keyset = "WHERE score < @cscore OR (score = @cscore AND id > @cid)"
+	}
+
+	type result struct {
+		ID          uint    `json:"id"`
+		Username    string  `json:"username"`
+		DisplayName string  `json:"display_name"`
+		ProfilePic  *string `json:"profile_pic"`
+		Type        string  `json:"type"`
+		Following   bool    `json:"following"`
+		FollowedBy  bool    `json:"followed_by"`
+		Score       float64 `json:"-"`
+	}
+
+	var rows []result
+	err = config.DB.Raw(`
+		SELECT * FROM (
+			SELECT r.*,
+				-- text match
+				(CASE WHEN lower(r.username) LIKE @prefix THEN 1.0 ELSE 0 END)
+				+ (CASE WHEN lower(r.display_name) LIKE @prefix THEN 0.5 ELSE 0 END)
+				+ GREATEST(similarity(lower(r.username), @q), similarity(lower(r.display_name), @q))
+				-- social proximity
+				+ (CASE WHEN r.following AND r.followed_by THEN 0.6 WHEN r.following THEN 0.3 ELSE 0 END)
+				+ 0.1 * LEAST(r.interactions, 5) AS score
+			FROM (
+				SELECT u.id, u.username, u.display_name, u.profile_pic, u.type,
+					EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = @me AND f.followee_id = u.id) AS following,
+					EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = u.id AND f.followee_id = @me) AS followed_by,
+					(SELECT COUNT(*) FROM story_views sv JOIN stories s ON s.id = sv.story_id
+						WHERE sv.viewer_id = @me AND s.user_id = u.id AND sv.viewed_at > @since) AS interactions
+				FROM users u
+				WHERE u.id <> @me
+					AND `+internal.ActiveUserSQL("u")+`
+					AND (lower(u.username) LIKE @prefix OR lower(u.display_name) LIKE @prefix
+						OR lower(u.username) % @q OR lower(u.display_name) % @q)
+					AND NOT EXISTS (SELECT 1 FROM blocks b
+						WHERE (b.blocker_id = @me AND b.blocked_id = u.id) OR (b.blocker_id = u.id AND b.blocked_id = @me))
+			) r
+		) ranked
+		`+keyset+`
+		ORDER BY score DESC, id ASC
+		LIMIT @limit`, params).
+		Scan(&rows).Error
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "search failed"})
+	}
+
+	var nextCursor *string
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		cursor := utils.EncodeCursor(last.Score, last.ID)
+		nextCursor = &cursor
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"users":       rows,
+		"next_cursor": nextCursor,
+	})
+}
+
+// ---------- Suggestions: GET /users/suggestions?limit= ----------
+// Served from the per-user cache. Until the background job has filled it,
+// popular accounts are served instead.
+func GetSuggestions(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+	limit := utils.PageLimit(c.QueryParam("limit"), 20, 50)
+
+	var cached int64
+	if err := config.DB.Model(&models.UserSuggestion{}).Where("user_id = ?", userID).Count(&cached).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if cached == 0 {
+		if err := internal.RequestSuggestions(userID); err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+		return popularSuggestions(c, userID, limit)
+	}
+
+	type suggestion struct {
+		ID          uint    `json:"id"`
+		Username    string  `json:"username"`
+		DisplayName string  `json:"display_name"`
+		ProfilePic  *string `json:"profile_pic"`
+		Reason      string  `json:"reason"`
+		MutualCount int     `json:"mutual_count"`
+	}
+
+	// The cache can be up to SuggestionsTTL old, so re-check what changed since
+	var rows []suggestion
+	if err := config.DB.
+		Table("user_suggestions AS s").
+		Select("u.id, u.username, u.display_name, u.profile_pic, s.reason, s.mutual_count").
+		Joins("JOIN users AS u ON u.id = s.suggested_id AND "+internal.ActiveUserSQL("u")).
+		Where("s.user_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = s.user_id AND f.followee_id = s.suggested_id)").
+		Where("NOT EXISTS (SELECT 1 FROM follow_requests r WHERE r.follower_id = s.user_id AND r.followee_id = s.suggested_id)").
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = s.user_id AND b.blocked_id = s.suggested_id) OR (b.blocker_id = s.suggested_id AND b.blocked_id = s.user_id))").
+		Order("s.score DESC, u.id ASC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"suggestions": rows})
+}
+
+// ---------- Dismiss suggestion: POST /users/suggestions/:id/dismiss ----------
+func DismissSuggestion(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	suggestedID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || suggestedID <= 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"})
+	}
+	var exists int64
+	if err := config.DB.Model(&models.User{}).Where("id = ?", suggestedID).Count(&exists).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if exists == 0 {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		dismissal := models.SuggestionDismissal{UserID: userID, SuggestedID: uint(suggestedID)}
+		if err := tx.Where(dismissal).FirstOrCreate(&dismissal).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.UserSuggestion{}, "user_id = ? AND suggested_id = ?", userID, suggestedID).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "suggestion dismissed"})
+}
+
+// ---------- Block: POST /users/:identifier/block ----------
+// Blocking removes follows and pending requests in both directions.
+func BlockUser(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if target.ID == userID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "cannot block yourself"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		block := models.Block{BlockerID: userID, BlockedID: target.ID}
+		if err := tx.Where(block).FirstOrCreate(&block).Error; err != nil {
+			return err
+		}
+		pair := "(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)"
+		if err := tx.Where(pair, userID, target.ID, target.ID, userID).Delete(&models.Follow{}).Error; err != nil {
+			return err
+		}
+		if err := internal.PruneTimeline(tx, userID, target.ID); err != nil {
+			return err
+		}
+		if err := internal.PruneTimeline(tx, target.ID, userID); err != nil {
+			return err
+		}
+		return tx.Where(pair, userID, target.ID, target.ID, userID).Delete(&models.FollowRequest{}).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "blocked"})
+}
+
+// ---------- Unblock: DELETE /users/:identifier/block ----------
+func UnblockUser(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	if err := config.DB.Delete(&models.Block{}, "blocker_id = ? AND blocked_id = ?", userID, target.ID).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "unblocked"})
+}
+
+// ---------- Mute: POST /users/:identifier/mute ----------
+// Body {"stories": bool, "posts": bool}; an empty body mutes both.
+type muteReq struct {
+	Stories *bool `json:"stories"`
+	Posts   *bool `json:"posts"`
+}
+
+func MuteUser(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	var req muteReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid input"})
+	}
+	if req.Stories == nil && req.Posts == nil {
+		yes := true
+		req.Stories, req.Posts = &yes, &yes
+	}
+
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+	if target.ID == userID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "cannot mute yourself"})
+	}
+
+	mute := models.Mute{MuterID: userID, MutedID: target.ID}
+	if err := config.DB.Where(mute).FirstOrInit(&mute).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+	if req.Stories != nil {
+		mute.Stories = *req.Stories
+	}
+	if req.Posts != nil {
+		mute.Posts = *req.Posts
+	}
+
+	// Nothing left muted: same as unmuting
+	if !mute.Stories && !mute.Posts {
+		if err := config.DB.Delete(&models.Mute{}, "muter_id = ? AND muted_id = ?", userID, target.ID).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"message": "unmuted"})
+	}
+
+	if err := config.DB.Save(&mute).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "muted",
+		"stories": mute.Stories,
+		"posts":   mute.Posts,
+	})
+}
+
+// ---------- Unmute: DELETE /users/:identifier/mute ----------
+func UnmuteUser(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"})
+	}
+
+	target, err := findUserByIdentifier(c.Param("identifier"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "user not found"})
+	}
+
+	if err := config.DB.Delete(&models.Mute{}, "muter_id = ? AND muted_id = ?", userID, target.ID).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"message": "unmuted"})
+}
+
+// -------------------- Helpers --------------------
+
+// popularSuggestions is the GetSuggestions fallback for users whose cache
+// hasn't been built yet.
+func popularSuggestions(c echo.Context, userID uint, limit int) error {
+	type suggestion struct {
+		ID          uint    `json:"id"`
+		Username    string  `json:"username"`
+		DisplayName string  `json:"display_name"`
+		ProfilePic  *string `json:"profile_pic"`
+		Reason      string  `json:"reason"`
+		MutualCount int     `json:"mutual_count"`
+	}
+
+	rows := []suggestion{}
+	popular := internal.PopularAccounts()
+	if len(popular) == 0 {
+		return c.JSON(http.StatusOK, echo.Map{"suggestions": rows})
+	}
+
+	if err := config.DB.
+		Table("users AS u").
+		Select("u.id, u.username, u.display_name, u.profile_pic, 'popular' AS reason, 0 AS mutual_count").
+		Where("u.id IN ? AND u.id <> ? AND "+internal.ActiveUserSQL("u"), popular, userID).
+		Where("NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.followee_id = u.id)", userID).
+		Where("NOT EXISTS (SELECT 1 FROM follow_requests r WHERE r.follower_id = ? AND r.followee_id = u.id)", userID).
+		Where("NOT EXISTS (SELECT 1 FROM suggestion_dismissals d WHERE d.user_id = ? AND d.suggested_id = u.id)", userID).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = u.id) OR (b.blocker_id = u.id AND b.blocked_id = ?))", userID, userID).
+		Scan(&rows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "db error"})
+	}
+
+	// Most followed first
+	rank := make(map[uint]int, len(popular))
+	for i, id := range popular {
+		rank[id] = i
+	}
+	sort.Slice(rows, func(i, j int) bool { return rank[rows[i].ID] < rank[rows[j].ID] })
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"suggestions": rows})
+}
+
+// findUserByIdentifier looks up an active (not deleted, banned or suspended)
+// user by id or username.
+func findUserByIdentifier(identifier string) (models.User, error) {
+	var user models.User
+	q := config.DB.Where(internal.ActiveUserSQL("users"))
+	if id, err := strconv.Atoi(identifier); err == nil {
+		err := q.First(&user, id).Error
+		return user, err
+	}
+	err := q.Where("username = ?", identifier).First(&user).Error
+	return user, err
+}
+
+// relationship is how the viewer relates to another user.
+type relationship struct {
+	Following  bool `json:"following"`   // viewer follows target
+	Requested  bool `json:"requested"`   // viewer has a pending follow request
+	FollowedBy bool `json:"followed_by"` // target follows viewer
+	Blocked    bool `json:"blocked"`     // viewer blocked target
+	BlockedBy  bool `json:"-"`           // target blocked viewer (never exposed)
+}
+
+func relationshipBetween(viewerID, targetID uint) (relationship, error) {
+	var rel relationship
+	if viewerID == targetID {
+		return rel, nil
+	}
+	err := config.DB.Raw(`
+		SELECT
+			EXISTS (SELECT 1 FROM follows WHERE follower_id = @viewer AND followee_id = @target) AS following,
+			EXISTS (SELECT 1 FROM follow_requests WHERE follower_id = @viewer AND followee_id = @target) AS requested,
+			EXISTS (SELECT 1 FROM follows WHERE follower_id = @target AND followee_id = @viewer) AS followed_by,
+			EXISTS (SELECT 1 FROM blocks WHERE blocker_id = @viewer AND blocked_id = @target) AS blocked,
+			EXISTS (SELECT 1 FROM blocks WHERE blocker_id = @target AND blocked_id = @viewer) AS blocked_by`,
+		map[string]interface{}{"viewer": viewerID, "target": targetID}).
+		Scan(&rel).Error
+	return rel, err
+}
+
+// isBlockedEitherWay reports whether a and b have blocked each other in any direction.
+func isBlockedEitherWay(a, b uint) (bool, error) {
+	var count int64
+	err := config.DB.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// followedBySummary builds "Followed by alice, bob and 12 others" from up to three names.
+func followedBySummary(names []string, total int64) string {
+	if total == 0 {
+		return ""
+	}
+	if len(names) > 3 {
+		names = names[:3]
+	}
+	others := total - int64(len(names))
+	switch {
+	case others == 1:
+		return fmt.Sprintf("Followed by %s and 1 other", strings.Join(names, ", "))
+	case others > 1:
+		return fmt.Sprintf("Followed by %s and %d others", strings.Join(names, ", "), others)
+	case len(names) == 1:
+		return "Followed by " + names[0]
+	default:
+		return fmt.Sprintf("Followed by %s and %s", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+	}
+}
+
+// escapeLike escapes LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func profileResponse(user models.User) echo.Map {
+	return echo.Map{
+		"id":           user.ID,
+		"username":     user.Username,
+		"display_name": user.DisplayName,
+		"bio":          user.Bio,
+		"website":      user.Website,
+		"pronouns":     user.Pronouns,
+		"profile_pic":  user.ProfilePic,
+		"type":         user.Type,
+		"created_at":   user.CreatedAt,
+	}
+}
@@ -8,9 +8,88 @@ import (
 	"story-backend/models"
 )
 
-func DeleteExpiredStories() {
-	result := config.DB.Where("expires_at <= ?", time.Now()).Delete(&models.Story{})
-	if result.Error == nil && result.RowsAffected > 0 {
-		fmt.Printf("🗑 Deleted %d expired stories\n", result.RowsAffected)
+// ArchiveExpiredStories moves expired stories into their owner's private
+// archive. Owners who turned archiving off get them deleted instead.
+func ArchiveExpiredStories() {
+	now := time.Now()
+
+	if err := pruneExpiredStoryItems(now); err != nil {
+		fmt.Println("❌ Failed to prune expired stories from timelines:", err)
+	}
+
+	archiving := config.DB.Model(&models.User{}).Select("id").Where("story_archive_enabled")
+	result := config.DB.Model(&models.Story{}).
+		Where("status = ? AND expires_at <= ? AND archived_at IS NULL AND user_id IN (?)", "published", now, archiving).
+		Update("archived_at", now)
+	if result.Error != nil {
+		fmt.Println("❌ Failed to archive expired stories:", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		fmt.Printf("📦 Archived %d expired stories\n", result.RowsAffected)
+	}
+
+	var ids []uint
+	if err := config.DB.Model(&models.Story{}).
+		Where("status = ? AND expires_at <= ? AND archived_at IS NULL", "published", now).
+		Pluck("id", &ids).Error; err != nil {
+		fmt.Println("❌ Failed to find expired stories:", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := DeleteStoryRows(config.DB, ids); err != nil {
+		fmt.Println("❌ Failed to delete expired stories:", err)
+		return
+	}
+	fmt.Printf("🗑 Deleted %d expired stories\n", len(ids))
+}
+
+// PurgeArchivedStories deletes archived stories older than config.StoryArchiveRetention.
+// A retention of 0 keeps the archive forever.
+func PurgeArchivedStories() {
+	if config.StoryArchiveRetention == 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-config.StoryArchiveRetention)
+	var ids []uint
+	if err := config.DB.Model(&models.Story{}).
+		Where("archived_at IS NOT NULL AND archived_at <= ?", cutoff).
+		Pluck("id", &ids).Error; err != nil {
+		fmt.Println("❌ Failed to find old archived stories:", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := DeleteStoryRows(config.DB, ids); err != nil {
+		fmt.Println("❌ Failed to purge archived stories:", err)
+		return
+	}
+	fmt.Printf("🗑 Purged %d archived stories past retention\n", len(ids))
+}
+
+// PruneRateLimitBuckets drops Postgres rate limit buckets idle for longer
+// than the longest limit window. They'd be full again, so nothing is lost.
+func PruneRateLimitBuckets() {
+	if config.RateLimitStore != "postgres" {
+		return
+	}
+	var window time.Duration
+	for _, limit := range config.RateLimits {
+		if limit.Per > window {
+			window = limit.Per
+		}
+	}
+
+	result := config.DB.Where("refilled_at < ?", time.Now().Add(-window)).Delete(&models.RateLimitBucket{})
+	if result.Error != nil {
+		fmt.Println("❌ Failed to prune rate limit buckets:", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		fmt.Printf("🗑 Pruned %d idle rate limit buckets\n", result.RowsAffected)
 	}
 }
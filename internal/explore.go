@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	exploreWindow       = 7 * 24 * time.Hour // how far back candidates go
+	exploreMaxPerAuthor = 3                  // candidates kept per author
+	explorePoolSize     = 5000
+)
+
+// RefreshExploreCandidates rebuilds the explore pool from recent posts by
+// public accounts. A post scores by engagement velocity: recent likes and
+// saves (saves count double) divided by a power of its age in hours, so
+// fresh posts that are picking up engagement rank first. Each author keeps
+// at most exploreMaxPerAuthor posts, and every further post by the same
+// author has its score halved, so no one dominates a page.
+func RefreshExploreCandidates() {
+	now := time.Now()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ExploreCandidate{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO explore_candidates (post_id, author_id, score, computed_at)
+			SELECT post_id, author_id, velocity * power(0.5, author_rank - 1), @now::timestamptz
+			FROM (
+				SELECT v.*, row_number() OVER (PARTITION BY author_id ORDER BY velocity DESC, post_id DESC) AS author_rank
+				FROM (
+					SELECT p.id AS post_id, p.user_id AS author_id,
+						(1
+							+ (SELECT COUNT(*) FROM post_likes l WHERE l.post_id = p.id AND l.created_at > @recent)
+							+ 2 * (SELECT COUNT(*) FROM saved_posts s WHERE s.post_id = p.id AND s.created_at > @recent))
+						/ power(EXTRACT(EPOCH FROM (@now::timestamptz - p.created_at)) / 3600 + 2, 1.5) AS velocity
+					FROM posts p
+					JOIN users u ON u.id = p.user_id
+					WHERE p.status = 'published'
+						AND p.created_at > @since
+						AND u.type = 'public'
+						AND `+ActiveUserSQL("u")+`
+				) v
+			) ranked
+			WHERE author_rank <= @per_author
+			ORDER BY 3 DESC
+			LIMIT @pool`,
+			map[string]interface{}{
+				"now":        now,
+				"since":      now.Add(-exploreWindow),
+				"recent":     now.Add(-24 * time.Hour),
+				"per_author": exploreMaxPerAuthor,
+				"pool":       explorePoolSize,
+			}).Error
+	})
+	if err != nil {
+		fmt.Println("❌ Failed to refresh explore candidates:", err)
+	}
+}
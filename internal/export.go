@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+)
+
+// exportUser is the row shape used for followers, following and requests.
+type exportUser struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// RunDataExport builds the ZIP for a pending export, then notifies the user
+// that it can be downloaded until it expires. Safe to call more than once per export:
+// only the caller that claims it (pending -> running) does the work.
+func RunDataExport(exportID uint) {
+	claim := config.DB.Model(&models.DataExport{}).
+		Where("id = ? AND status = ?", exportID, "pending").
+		Updates(map[string]interface{}{"status": "running", "started_at": time.Now()})
+	if claim.Error != nil || claim.RowsAffected == 0 {
+		return
+	}
+
+	var export models.DataExport
+	if err := config.DB.First(&export, exportID).Error; err != nil {
+		fmt.Printf("❌ Export %d vanished: %v\n", exportID, err)
+		return
+	}
+
+	path, err := writeExportZip(export)
+	if err != nil {
+		fmt.Printf("❌ Export %d failed: %v\n", exportID, err)
+		config.DB.Model(&export).Update("status", "failed")
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&export).Updates(map[string]interface{}{
+		"status":       "ready",
+		"file_path":    path,
+		"expires_at":   now.Add(config.ExportLinkTTL),
+		"completed_at": now,
+	}).Error; err != nil {
+		fmt.Printf("❌ Export %d could not be saved: %v\n", exportID, err)
+		return
+	}
+
+	// No link here: notifications are stored in plain text. The owner gets
+	// a fresh download link from GET /me/export/:id
+	Notify(models.Notification{
+		UserID:     export.UserID,
+		Type:       "data_export_ready",
+		EntityType: "data_export",
+		EntityID:   &export.ID,
+		Message:    "Your data export is ready to download.",
+	})
+}
+
+// ProcessDataExports picks up exports that were never started or whose
+// worker died (e.g. the server restarted mid-export).
+func ProcessDataExports() {
+	config.DB.Model(&models.DataExport{}).
+		Where("status = ? AND (started_at IS NULL OR started_at <= ?)", "running", time.Now().Add(-30*time.Minute)).
+		Update("status", "pending")
+
+	var ids []uint
+	if err := config.DB.Model(&models.DataExport{}).Where("status = ?", "pending").Pluck("id", &ids).Error; err != nil {
+		fmt.Println("❌ Export lookup failed:", err)
+		return
+	}
+	for _, id := range ids {
+		RunDataExport(id)
+	}
+}
+
+// DeleteExpiredExports removes files whose download link has expired.
+func DeleteExpiredExports() {
+	var exports []models.DataExport
+	if err := config.DB.Where("status = ? AND expires_at <= ?", "ready", time.Now()).Find(&exports).Error; err != nil {
+		fmt.Println("❌ Expired export lookup failed:", err)
+		return
+	}
+	for _, e := range exports {
+		if err := os.Remove(e.FilePath); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("❌ Could not remove export %d: %v\n", e.ID, err)
+			continue
+		}
+		config.DB.Model(&e).Updates(map[string]interface{}{"status": "expired", "token_hash": nil})
+	}
+}
+
+// -------------------- ZIP building --------------------
+
+func writeExportZip(export models.DataExport) (string, error) {
+	if err := os.MkdirAll(config.ExportDir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(config.ExportDir, fmt.Sprintf("export-%d-%d.zip", export.UserID, export.ID))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	if err := writeExportFiles(zw, export.UserID); err != nil {
+		zw.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func writeExportFiles(zw *zip.Writer, userID uint) error {
+	db := config.DB
+
+	var profile models.User
+	if err := db.First(&profile, userID).Error; err != nil {
+		return err
+	}
+
+	var followers, following []exportUser
+	if err := db.Table("follows").Select("users.id, users.username").
+		Joins("JOIN users ON users.id = follows.follower_id").
+		Where("follows.followee_id = ?", userID).Scan(&followers).Error; err != nil {
+		return err
+	}
+	if err := db.Table("follows").Select("users.id, users.username").
+		Joins("JOIN users ON users.id = follows.followee_id").
+		Where("follows.follower_id = ?", userID).Scan(&following).Error; err != nil {
+		return err
+	}
+
+	var requestsReceived, requestsSent []exportUser
+	if err := db.Table("follow_requests").Select("users.id, users.username").
+		Joins("JOIN users ON users.id = follow_requests.follower_id").
+		Where("follow_requests.followee_id = ?", userID).Scan(&requestsReceived).Error; err != nil {
+		return err
+	}
+	if err := db.Table("follow_requests").Select("users.id, users.username").
+		Joins("JOIN users ON users.id = follow_requests.followee_id").
+		Where("follow_requests.follower_id = ?", userID).Scan(&requestsSent).Error; err != nil {
+		return err
+	}
+
+	var posts []models.Post
+	if err := db.Preload("Media", OrderedMedia).Where("user_id = ?", userID).Order("created_at").Find(&posts).Error; err != nil {
+		return err
+	}
+
+	// Every stored story, expired or not
+	var stories []models.Story
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&stories).Error; err != nil {
+		return err
+	}
+
+	var viewsMade, viewsReceived []models.StoryView
+	if err := db.Where("viewer_id = ?", userID).Order("viewed_at").Find(&viewsMade).Error; err != nil {
+		return err
+	}
+	if err := db.Joins("JOIN stories ON stories.id = story_views.story_id").
+		Where("stories.user_id = ?", userID).
+		Order("story_views.viewed_at").
+		Find(&viewsReceived).Error; err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"followers.json", followers},
+		{"following.json", following},
+		{"follow_requests.json", map[string]interface{}{"received": requestsReceived, "sent": requestsSent}},
+		{"posts.json", posts},
+		{"stories.json", stories},
+		{"story_views_made.json", viewsMade},
+		{"story_views_received.json", viewsReceived},
+	}
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
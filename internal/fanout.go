@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// How far back a new follow copies an author's posts into the timeline.
+// Post entries older than this are pruned, so every timeline covers the
+// same window.
+const timelineBackfill = 90 * 24 * time.Hour
+
+const timelinePruneBatch = 10000
+
+// FanOutPost copies a published post into its author's followers'
+// timelines. Safe to call more than once: only the first call that claims
+// the post does the work. Handlers call it in a goroutine as a fast path;
+// ProcessFanOut is what guarantees it happens.
+func FanOutPost(postID uint) {
+	if err := fanOut(&models.Post{}, "post_id", postID); err != nil {
+		fmt.Printf("❌ Fan-out of post %d failed: %v\n", postID, err)
+	}
+}
+
+// FanOutStory is FanOutPost for stories. Viewers the owner hid their
+// stories from are skipped.
+func FanOutStory(storyID uint) {
+	if err := fanOut(&models.Story{}, "story_id", storyID); err != nil {
+		fmt.Printf("❌ Fan-out of story %d failed: %v\n", storyID, err)
+	}
+}
+
+// ProcessFanOut picks up published content that hasn't been fanned out yet,
+// e.g. when the process restarted before the background fan-out ran.
+func ProcessFanOut() {
+	var postIDs []uint
+	if err := config.DB.Model(&models.Post{}).
+		Where("status = ? AND fanned_out_at IS NULL", "published").
+		Order("id").Limit(500).
+		Pluck("id", &postIDs).Error; err != nil {
+		fmt.Println("❌ Failed to find posts to fan out:", err)
+	}
+	for _, id := range postIDs {
+		FanOutPost(id)
+	}
+
+	var storyIDs []uint
+	if err := config.DB.Model(&models.Story{}).
+		Where("status = ? AND fanned_out_at IS NULL AND expires_at > ?", "published", time.Now()).
+		Order("id").Limit(500).
+		Pluck("id", &storyIDs).Error; err != nil {
+		fmt.Println("❌ Failed to find stories to fan out:", err)
+	}
+	for _, id := range storyIDs {
+		FanOutStory(id)
+	}
+}
+
+// fanOut claims one post or story and writes its timeline entries in the
+// same transaction, so a failure leaves it unclaimed for ProcessFanOut.
+// Authors above config.FanoutFollowerThreshold are marked read-time instead.
+func fanOut(model interface{}, column string, id uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		claimed := tx.Model(model).
+			Where("id = ? AND status = ? AND fanned_out_at IS NULL", id, "published").
+			Update("fanned_out_at", time.Now())
+		if claimed.Error != nil || claimed.RowsAffected == 0 {
+			return claimed.Error
+		}
+
+		var item struct {
+			UserID    uint
+			CreatedAt time.Time
+		}
+		if err := tx.Model(model).Select("user_id", "created_at").Where("id = ?", id).Scan(&item).Error; err != nil {
+			return err
+		}
+
+		var followers int64
+		if err := tx.Model(&models.Follow{}).Where("followee_id = ?", item.UserID).Count(&followers).Error; err != nil {
+			return err
+		}
+		if followers > int64(config.FanoutFollowerThreshold) {
+			return tx.Model(model).Where("id = ?", id).Update("fanout_on_read", true).Error
+		}
+
+		hidden := ""
+		if column == "story_id" {
+			hidden = "AND NOT EXISTS (SELECT 1 FROM story_hidden_from h WHERE h.owner_id = @author AND h.viewer_id = f.follower_id)"
+		}
+		return tx.Exec(`
+			INSERT INTO feed_items (user_id, author_id, `+column+`, created_at)
+			SELECT f.follower_id, @author, @id, @created_at
+			FROM follows f
+			WHERE f.followee_id = @author `+hidden+`
+			ON CONFLICT DO NOTHING`,
+			map[string]interface{}{"author": item.UserID, "id": id, "created_at": item.CreatedAt}).Error
+	})
+}
+
+// BackfillTimeline copies an author's recent posts and live stories into a
+// new follower's timeline. Read-time content is left out; the feeds find it
+// through the follow.
+func BackfillTimeline(tx *gorm.DB, followerID, authorID uint) error {
+	params := map[string]interface{}{
+		"follower": followerID,
+		"author":   authorID,
+		"since":    time.Now().Add(-timelineBackfill),
+		"now":      time.Now(),
+	}
+	if err := tx.Exec(`
+		INSERT INTO feed_items (user_id, author_id, post_id, created_at)
+		SELECT @follower, @author, p.id, p.created_at
+		FROM posts p
+		WHERE p.user_id = @author AND p.status = 'published' AND p.fanned_out_at IS NOT NULL
+			AND NOT p.fanout_on_read AND p.created_at > @since
+		ON CONFLICT DO NOTHING`, params).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		INSERT INTO feed_items (user_id, author_id, story_id, created_at)
+		SELECT @follower, @author, s.id, s.created_at
+		FROM stories s
+		WHERE s.user_id = @author AND s.status = 'published' AND s.fanned_out_at IS NOT NULL
+			AND NOT s.fanout_on_read AND s.expires_at > @now
+			AND NOT EXISTS (SELECT 1 FROM story_hidden_from h WHERE h.owner_id = @author AND h.viewer_id = @follower)
+		ON CONFLICT DO NOTHING`, params).Error
+}
+
+// PruneTimeline removes an author's posts and stories from a user's
+// timeline, after an unfollow, a removed follower or a block.
+func PruneTimeline(tx *gorm.DB, userID, authorID uint) error {
+	return tx.Where("user_id = ? AND author_id = ?", userID, authorID).Delete(&models.FeedItem{}).Error
+}
+
+// PruneStoryTimeline removes only an owner's stories from a viewer's
+// timeline, when the owner hides their stories from them.
+func PruneStoryTimeline(tx *gorm.DB, viewerID, ownerID uint) error {
+	return tx.Where("user_id = ? AND author_id = ? AND story_id IS NOT NULL", viewerID, ownerID).
+		Delete(&models.FeedItem{}).Error
+}
+
+// PruneOldTimelinePosts drops post entries older than timelineBackfill, in
+// batches so a large backlog doesn't hold long locks.
+func PruneOldTimelinePosts() {
+	cutoff := time.Now().Add(-timelineBackfill)
+	var total int64
+	for {
+		batch := config.DB.Model(&models.FeedItem{}).Select("id").
+			Where("post_id IS NOT NULL AND created_at < ?", cutoff).
+			Limit(timelinePruneBatch)
+		result := config.DB.Where("id IN (?)", batch).Delete(&models.FeedItem{})
+		if result.Error != nil {
+			fmt.Println("❌ Failed to prune old timeline posts:", result.Error)
+			return
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < timelinePruneBatch {
+			break
+		}
+	}
+	if total > 0 {
+		fmt.Printf("🗑 Pruned %d old timeline posts\n", total)
+	}
+}
+
+// pruneExpiredStoryItems drops timeline entries of stories that left the feed.
+func pruneExpiredStoryItems(now time.Time) error {
+	expired := config.DB.Model(&models.Story{}).Select("id").Where("expires_at <= ?", now)
+	return config.DB.Where("story_id IN (?)", expired).Delete(&models.FeedItem{}).Error
+}
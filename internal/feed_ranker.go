@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// FeedCandidate is a post eligible for the home feed plus the signals the
+// ranker may use.
+type FeedCandidate struct {
+	PostID    uint
+	AuthorID  uint
+	CreatedAt time.Time
+	LikeCount int64
+
+	// Closeness: how much the viewer engaged with the author lately
+	AuthorStoryViews int64
+	AuthorLikes      int64
+}
+
+// Ranker scores home feed candidates; higher comes first. Scores must only
+// depend on the candidate and now so the order is reproducible.
+type Ranker interface {
+	Score(c FeedCandidate, now time.Time) float64
+}
+
+// DefaultRanker weighs recency, closeness to the author and engagement.
+type DefaultRanker struct {
+	RecencyHalfLife  time.Duration
+	RecencyWeight    float64
+	ClosenessWeight  float64
+	EngagementWeight float64
+}
+
+// NewDefaultRanker returns the ranker used by GET /posts/feed?mode=ranked.
+func NewDefaultRanker() DefaultRanker {
+	return DefaultRanker{
+		RecencyHalfLife:  12 * time.Hour,
+		RecencyWeight:    1.0,
+		ClosenessWeight:  0.5,
+		EngagementWeight: 0.3,
+	}
+}
+
+func (r DefaultRanker) Score(c FeedCandidate, now time.Time) float64 {
+	age := now.Sub(c.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+	recency := math.Pow(0.5, age.Hours()/r.RecencyHalfLife.Hours())
+	// A like says more than a story view
+	closeness := math.Log1p(float64(c.AuthorStoryViews) + 2*float64(c.AuthorLikes))
+	engagement := math.Log1p(float64(c.LikeCount))
+
+	return r.RecencyWeight*recency + r.ClosenessWeight*closeness + r.EngagementWeight*engagement
+}
+
+// RankFeed orders candidates by score. Ties fall back to newest first, then
+// highest post id, so equal inputs always give the same order.
+func RankFeed(r Ranker, candidates []FeedCandidate, now time.Time) []FeedCandidate {
+	scores := make(map[uint]float64, len(candidates))
+	for _, c := range candidates {
+		scores[c.PostID] = r.Score(c, now)
+	}
+
+	ranked := append([]FeedCandidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if scores[a.PostID] != scores[b.PostID] {
+			return scores[a.PostID] > scores[b.PostID]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.PostID > b.PostID
+	})
+	return ranked
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var rankNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestDefaultRankerScore(t *testing.T) {
+	r := NewDefaultRanker()
+
+	tests := []struct {
+		name string
+		c    FeedCandidate
+		want float64
+	}{
+		{"brand new, no signals", FeedCandidate{CreatedAt: rankNow}, 1.0},
+		{"one half-life old", FeedCandidate{CreatedAt: rankNow.Add(-12 * time.Hour)}, 0.5},
+		{"future timestamps count as new", FeedCandidate{CreatedAt: rankNow.Add(time.Hour)}, 1.0},
+		{
+			"closeness and engagement",
+			FeedCandidate{CreatedAt: rankNow, AuthorStoryViews: 1, AuthorLikes: 1, LikeCount: 9},
+			1.0 + 0.5*math.Log1p(3) + 0.3*math.Log1p(9),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Score(tt.c, rankNow); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankFeedOrder(t *testing.T) {
+	candidates := []FeedCandidate{
+		{PostID: 1, CreatedAt: rankNow.Add(-24 * time.Hour)},                 // old, no signals
+		{PostID: 2, CreatedAt: rankNow.Add(-1 * time.Hour)},                  // fresh
+		{PostID: 3, CreatedAt: rankNow.Add(-6 * time.Hour), AuthorLikes: 20}, // close author
+		{PostID: 4, CreatedAt: rankNow.Add(-24 * time.Hour), LikeCount: 500}, // popular but old
+	}
+
+	got := ids(RankFeed(NewDefaultRanker(), candidates, rankNow))
+	want := []uint{3, 4, 2, 1}
+	if !equalIDs(got, want) {
+		t.Fatalf("RankFeed() = %v, want %v", got, want)
+	}
+
+	// Input order must not matter, and the input is left alone
+	reversed := []FeedCandidate{candidates[3], candidates[2], candidates[1], candidates[0]}
+	if got := ids(RankFeed(NewDefaultRanker(), reversed, rankNow)); !equalIDs(got, want) {
+		t.Fatalf("RankFeed(reversed) = %v, want %v", got, want)
+	}
+	if reversed[0].PostID != 4 {
+		t.Fatalf("RankFeed modified its input")
+	}
+}
+
+// constRanker scores everything the same, so only the tie-breaks decide.
+type constRanker struct{}
+
+func (constRanker) Score(FeedCandidate, time.Time) float64 { return 1 }
+
+func TestRankFeedTieBreaks(t *testing.T) {
+	candidates := []FeedCandidate{
+		{PostID: 5, CreatedAt: rankNow.Add(-2 * time.Hour)},
+		{PostID: 7, CreatedAt: rankNow.Add(-1 * time.Hour)},
+		{PostID: 9, CreatedAt: rankNow.Add(-2 * time.Hour)},
+	}
+
+	// Newest first, then highest post id
+	want := []uint{7, 9, 5}
+	if got := ids(RankFeed(constRanker{}, candidates, rankNow)); !equalIDs(got, want) {
+		t.Fatalf("RankFeed() = %v, want %v", got, want)
+	}
+}
+
+func ids(cands []FeedCandidate) []uint {
+	out := make([]uint, len(cands))
+	for i, c := range cands {
+		out[i] = c.PostID
+	}
+	return out
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"story-backend/models"
+	"story-backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SyncPostHashtags replaces a post's hashtag links with the tags found in
+// its caption, creating hashtags seen for the first time.
+func SyncPostHashtags(tx *gorm.DB, postID uint, caption string) error {
+	if err := tx.Where("post_id = ?", postID).Delete(&models.PostHashtag{}).Error; err != nil {
+		return err
+	}
+
+	names := utils.ParseHashtags(caption)
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags := make([]models.Hashtag, len(names))
+	for i, name := range names {
+		tags[i] = models.Hashtag{Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+
+	var ids []uint
+	if err := tx.Model(&models.Hashtag{}).Where("name IN ?", names).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	links := make([]models.PostHashtag, len(ids))
+	for i, id := range ids {
+		links[i] = models.PostHashtag{PostID: postID, HashtagID: id}
+	}
+	return tx.Create(&links).Error
+}
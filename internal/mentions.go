@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SyncMentions makes the mentions on a post ("post_id") or story
+// ("story_id") match usernames, skipping users whose mention policy or
+// blocks don't allow it, and users the author hides stories from.
+// Self-removed mentions stay removed. It returns the mentions that were
+// added, for NotifyMentions.
+func SyncMentions(tx *gorm.DB, column string, entityID, authorID uint, usernames []string) ([]models.Mention, error) {
+	var allowed []uint
+	if len(usernames) > 0 {
+		q := tx.Model(&models.User{}).
+			Where("username IN ? AND id <> ? AND "+ActiveUserSQL("users"), usernames, authorID).
+			Where(`(mention_policy = 'everyone' OR (mention_policy = 'following'
+				AND EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = users.id AND f.followee_id = ?)))`, authorID).
+			Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = users.id AND b.blocked_id = ?) OR (b.blocker_id = ? AND b.blocked_id = users.id))", authorID, authorID)
+		if column == "story_id" {
+			// They couldn't open the story anyway
+			q = q.Where("NOT EXISTS (SELECT 1 FROM story_hidden_from h WHERE h.owner_id = ? AND h.viewer_id = users.id)", authorID)
+		}
+		if err := q.Pluck("id", &allowed).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	stale := tx.Where(column+" = ? AND removed_at IS NULL", entityID)
+	if len(allowed) > 0 {
+		stale = stale.Where("user_id NOT IN ?", allowed)
+	}
+	if err := stale.Delete(&models.Mention{}).Error; err != nil {
+		return nil, err
+	}
+
+	var existing []uint
+	if err := tx.Model(&models.Mention{}).Where(column+" = ?", entityID).Pluck("user_id", &existing).Error; err != nil {
+		return nil, err
+	}
+	seen := map[uint]bool{}
+	for _, id := range existing {
+		seen[id] = true
+	}
+
+	added := []models.Mention{}
+	for _, id := range allowed {
+		if seen[id] {
+			continue
+		}
+		m := models.Mention{UserID: id, AuthorID: authorID}
+		ref := entityID
+		if column == "post_id" {
+			m.PostID = &ref
+		} else {
+			m.StoryID = &ref
+		}
+		added = append(added, m)
+	}
+	if len(added) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&added).Error; err != nil {
+			return nil, err
+		}
+	}
+	return added, nil
+}
+
+// NotifyMentions tells mentioned users about published content.
+func NotifyMentions(mentions []models.Mention) {
+	for _, m := range mentions {
+		var author models.User
+		if err := config.DB.Select("username").First(&author, m.AuthorID).Error; err != nil {
+			fmt.Printf("❌ Mention notification for user %d skipped: %v\n", m.UserID, err)
+			continue
+		}
+
+		entity, entityID, what := "post", m.PostID, "a post"
+		if m.StoryID != nil {
+			entity, entityID, what = "story", m.StoryID, "their story"
+		}
+		actor := m.AuthorID
+		Notify(models.Notification{
+			UserID:     m.UserID,
+			Type:       "mention",
+			ActorID:    &actor,
+			EntityType: entity,
+			EntityID:   entityID,
+			Message:    fmt.Sprintf("%s mentioned you in %s.", author.Username, what),
+		})
+	}
+}
+
+// notifyPublishedMentions notifies everyone still mentioned on content that
+// was just published by the scheduler.
+func notifyPublishedMentions(column string, ids []uint) {
+	if len(ids) == 0 {
+		return
+	}
+	var mentions []models.Mention
+	if err := config.DB.Where(column+" IN ? AND removed_at IS NULL", ids).Find(&mentions).Error; err != nil {
+		fmt.Println("❌ Failed to load mentions of published content:", err)
+		return
+	}
+	NotifyMentions(mentions)
+}
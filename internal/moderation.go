@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"time"
+
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// SuspendUser blocks logins until `until` and revokes existing sessions.
+// A longer suspension already in place is kept.
+func SuspendUser(tx *gorm.DB, userID uint, until time.Time) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"suspended_until": gorm.Expr("GREATEST(COALESCE(suspended_until, ?), ?)", until, until),
+		"token_version":   gorm.Expr("token_version + 1"),
+	}).Error
+}
+
+// ActiveUserSQL is the condition for accounts whose profile and content are
+// shown to others: not pending deletion, banned or suspended. alias is the
+// users table name or alias in the query.
+func ActiveUserSQL(alias string) string {
+	return alias + ".deletion_requested_at IS NULL AND " + alias + ".banned_at IS NULL AND (" +
+		alias + ".suspended_until IS NULL OR " + alias + ".suspended_until <= now())"
+}
@@ -0,0 +1,16 @@
+package internal
+
+import (
+	"fmt"
+
+	"story-backend/config"
+	"story-backend/models"
+)
+
+// Notify stores an in-app notification. Failures are logged, never returned:
+// a missed notification shouldn't fail the action that caused it.
+func Notify(n models.Notification) {
+	if err := config.DB.Create(&n).Error; err != nil {
+		fmt.Printf("❌ Failed to notify user %d (%s): %v\n", n.UserID, n.Type, err)
+	}
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// DeletePostRows hard-deletes posts and the rows that reference them.
+// postIDs can be a slice of ids or a subquery selecting ids.
+func DeletePostRows(tx *gorm.DB, postIDs interface{}) error {
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.PostMedia{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.PostLike{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.PostHashtag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.CollectionPost{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.SavedPost{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.ExploreCandidate{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", postIDs).Delete(&models.FeedItem{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN (?)", postIDs).Delete(&models.Post{}).Error
+}
+
+// OrderedMedia is a Preload scope that returns a post's media in carousel order.
+func OrderedMedia(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PublishScheduledContent puts scheduled stories and posts live once their
+// publish time has passed. created_at becomes the actual publish time so
+// feeds order them correctly, and story expiry counts from that moment.
+// Mentioned users are notified and followers' timelines are written once
+// the content is live.
+func PublishScheduledContent() {
+	now := time.Now()
+
+	// Claim and publish in one statement so only the rows this run moved
+	// (not ones another replica got to first, or rescheduled in between)
+	// are notified and fanned out.
+	var stories []models.Story
+	if err := config.DB.Model(&stories).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("status = ? AND publish_at <= ?", "scheduled", now).
+		Updates(map[string]interface{}{
+			"status":     "published",
+			"created_at": now,
+			"expires_at": gorm.Expr("?::timestamptz + ttl_minutes * interval '1 minute'", now),
+		}).Error; err != nil {
+		fmt.Println("❌ Failed to publish scheduled stories:", err)
+	} else if len(stories) > 0 {
+		storyIDs := make([]uint, len(stories))
+		for i, st := range stories {
+			storyIDs[i] = st.ID
+		}
+		fmt.Printf("📣 Published %d scheduled stories\n", len(storyIDs))
+		notifyPublishedMentions("story_id", storyIDs)
+		for _, id := range storyIDs {
+			FanOutStory(id)
+		}
+	}
+
+	var posts []models.Post
+	if err := config.DB.Model(&posts).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("status = ? AND publish_at <= ?", "scheduled", now).
+		Updates(map[string]interface{}{
+			"status":     "published",
+			"created_at": now,
+		}).Error; err != nil {
+		fmt.Println("❌ Failed to publish scheduled posts:", err)
+	} else if len(posts) > 0 {
+		postIDs := make([]uint, len(posts))
+		for i, p := range posts {
+			postIDs[i] = p.ID
+		}
+		fmt.Printf("📣 Published %d scheduled posts\n", len(postIDs))
+		notifyPublishedMentions("post_id", postIDs)
+		for _, id := range postIDs {
+			FanOutPost(id)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// PurgeDeletedAccounts removes users whose deletion grace period is over,
+// together with everything they created.
+func PurgeDeletedAccounts() {
+	cutoff := time.Now().Add(-config.AccountDeletionGrace)
+
+	var ids []uint
+	if err := config.DB.Model(&models.User{}).
+		Where("deletion_requested_at IS NOT NULL AND deletion_requested_at <= ?", cutoff).
+		Pluck("id", &ids).Error; err != nil {
+		fmt.Println("❌ Purge lookup failed:", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return purgeUser(tx, id)
+		}); err != nil {
+			fmt.Printf("❌ Purge of user %d failed: %v\n", id, err)
+			continue
+		}
+		fmt.Printf("🗑 Purged user %d\n", id)
+	}
+}
+
+func purgeUser(tx *gorm.DB, userID uint) error {
+	// Their stories (with views/events), then views and events they made elsewhere
+	ownStories := tx.Model(&models.Story{}).Select("id").Where("user_id = ?", userID)
+	if err := DeleteStoryRows(tx, ownStories); err != nil {
+		return err
+	}
+	if err := tx.Where("viewer_id = ?", userID).Delete(&models.StoryView{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("viewer_id = ?", userID).Delete(&models.StoryEvent{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("follower_id = ? OR followee_id = ?", userID, userID).Delete(&models.Follow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("follower_id = ? OR followee_id = ?", userID, userID).Delete(&models.FollowRequest{}).Error; err != nil {
+		return err
+	}
+	ownPosts := tx.Model(&models.Post{}).Select("id").Where("user_id = ?", userID)
+	if err := DeletePostRows(tx, ownPosts); err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.PostLike{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.HashtagFollow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? OR author_id = ?", userID, userID).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	ownCollections := tx.Model(&models.Collection{}).Select("id").Where("user_id = ?", userID)
+	if err := tx.Where("collection_id IN (?)", ownCollections).Delete(&models.CollectionPost{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Collection{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.SavedPost{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? OR author_id = ?", userID, userID).Delete(&models.FeedItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&models.Block{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? OR suggested_id = ?", userID, userID).Delete(&models.UserSuggestion{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? OR suggested_id = ?", userID, userID).Delete(&models.SuggestionDismissal{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("owner_id = ? OR user_id = ?", userID, userID).Delete(&models.FollowCooldown{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("muter_id = ? OR muted_id = ?", userID, userID).Delete(&models.Mute{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("owner_id = ? OR viewer_id = ?", userID, userID).Delete(&models.StoryHiddenFrom{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+	if err := removeExportFiles(tx, userID); err != nil {
+		return err
+	}
+	return tx.Delete(&models.User{}, userID).Error
+}
+
+func removeExportFiles(tx *gorm.DB, userID uint) error {
+	var exports []models.DataExport
+	if err := tx.Where("user_id = ?", userID).Find(&exports).Error; err != nil {
+		return err
+	}
+	for _, e := range exports {
+		if e.FilePath != "" {
+			os.Remove(e.FilePath)
+		}
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.DataExport{}).Error
+}
@@ -0,0 +1,29 @@
+package internal
+
+import "time"
+
+// StartJobs runs the periodic background jobs for the lifetime of the process.
+func StartJobs() {
+	go every(time.Minute, PublishScheduledContent)
+	go every(time.Minute, ProcessFanOut)
+	go every(time.Hour, PruneOldTimelinePosts)
+	go every(time.Minute, ArchiveExpiredStories)
+	go every(time.Hour, PurgeArchivedStories)
+	go every(time.Hour, PurgeDeletedAccounts)
+	go every(5*time.Minute, ProcessDataExports)
+	go every(time.Hour, DeleteExpiredExports)
+	go every(30*time.Minute, RefreshSuggestions)
+	go every(time.Minute, ProcessSuggestionRequests)
+	go every(30*time.Minute, RefreshPopularAccounts)
+	go every(10*time.Minute, RefreshExploreCandidates)
+	go every(10*time.Minute, PruneRateLimitBuckets)
+}
+
+func every(interval time.Duration, job func()) {
+	job()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		job()
+	}
+}
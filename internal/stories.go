@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// DeleteStoryRows hard-deletes stories and the rows that reference them.
+// storyIDs can be a slice of ids or a subquery selecting ids.
+func DeleteStoryRows(tx *gorm.DB, storyIDs interface{}) error {
+	if err := tx.Where("story_id IN (?)", storyIDs).Delete(&models.StoryEvent{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("story_id IN (?)", storyIDs).Delete(&models.StoryView{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("story_id IN (?)", storyIDs).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("story_id IN (?)", storyIDs).Delete(&models.FeedItem{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN (?)", storyIDs).Delete(&models.Story{}).Error
+}
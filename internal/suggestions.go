@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+const maxSuggestions = 50
+
+// popularAccounts is the cheap fallback served while a user's suggestions
+// are being computed. Refreshed by RefreshPopularAccounts.
+var (
+	popularMu       sync.RWMutex
+	popularAccounts []uint
+)
+
+// ComputeSuggestions rebuilds the cached suggestions for one user from the
+// follows graph: friends-of-friends ranked by mutual count, followers you
+// don't follow back, then popular accounts as a fallback.
+func ComputeSuggestions(userID uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserSuggestion{}).Error; err != nil {
+			return err
+		}
+		// Also marks users with no candidates as done, so they aren't retried until the TTL
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"suggestions_computed_at":  time.Now(),
+			"suggestions_requested_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO user_suggestions (user_id, suggested_id, reason, mutual_count, score, computed_at)
+			SELECT @me, best.suggested_id, best.reason, best.mutual_count, best.score, @now
+			FROM (
+				SELECT DISTINCT ON (suggested_id) suggested_id, reason, mutual_count, score
+				FROM (
+					-- people followed by people I follow
+					SELECT f2.followee_id AS suggested_id, 'mutual_follows' AS reason,
+						COUNT(*) AS mutual_count, 1.0 + COUNT(*) AS score
+					FROM follows f1
+					JOIN follows f2 ON f2.follower_id = f1.followee_id
+					WHERE f1.follower_id = @me
+					GROUP BY f2.followee_id
+
+					UNION ALL
+
+					-- people who follow me
+					SELECT f.follower_id, 'follows_you', 0, 2.5
+					FROM follows f
+					WHERE f.followee_id = @me
+
+					UNION ALL
+
+					-- most followed accounts
+					SELECT p.followee_id, 'popular', 0, LEAST(p.cnt, 1000) / 1000.0
+					FROM (
+						SELECT followee_id, COUNT(*) AS cnt FROM follows
+						GROUP BY followee_id ORDER BY cnt DESC LIMIT @max
+					) p
+				) candidates
+				ORDER BY suggested_id, score DESC
+			) best
+			JOIN users u ON u.id = best.suggested_id AND `+ActiveUserSQL("u")+`
+			WHERE best.suggested_id <> @me
+				AND NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = @me AND f.followee_id = best.suggested_id)
+				AND NOT EXISTS (SELECT 1 FROM follow_requests r WHERE r.follower_id = @me AND r.followee_id = best.suggested_id)
+				AND NOT EXISTS (SELECT 1 FROM suggestion_dismissals d WHERE d.user_id = @me AND d.suggested_id = best.suggested_id)
+				AND NOT EXISTS (SELECT 1 FROM blocks b
+					WHERE (b.blocker_id = @me AND b.blocked_id = best.suggested_id)
+						OR (b.blocker_id = best.suggested_id AND b.blocked_id = @me))
+			ORDER BY best.score DESC
+			LIMIT @max`,
+			map[string]interface{}{"me": userID, "now": time.Now(), "max": maxSuggestions}).Error
+	})
+}
+
+// ProcessSuggestionRequests computes suggestions for users who asked for
+// them with nothing cached (see RequestSuggestions).
+func ProcessSuggestionRequests() {
+	var ids []uint
+	if err := config.DB.Model(&models.User{}).
+		Where("suggestions_requested_at IS NOT NULL").
+		Order("suggestions_requested_at").
+		Limit(200).
+		Pluck("id", &ids).Error; err != nil {
+		fmt.Println("❌ Suggestion request lookup failed:", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := ComputeSuggestions(id); err != nil {
+			fmt.Printf("❌ Suggestions for user %d failed: %v\n", id, err)
+		}
+	}
+}
+
+// RequestSuggestions queues a user for ProcessSuggestionRequests, unless
+// their last (possibly empty) result is still fresh.
+func RequestSuggestions(userID uint) error {
+	return config.DB.Model(&models.User{}).
+		Where("id = ? AND suggestions_requested_at IS NULL", userID).
+		Where("suggestions_computed_at IS NULL OR suggestions_computed_at < ?", time.Now().Add(-config.SuggestionsTTL)).
+		Update("suggestions_requested_at", time.Now()).Error
+}
+
+// RefreshPopularAccounts reloads the most followed accounts.
+func RefreshPopularAccounts() {
+	var ids []uint
+	if err := config.DB.Raw(`
+		SELECT f.followee_id FROM follows f
+		JOIN users u ON u.id = f.followee_id AND `+ActiveUserSQL("u")+`
+		GROUP BY f.followee_id
+		ORDER BY COUNT(*) DESC, f.followee_id
+		LIMIT ?`, maxSuggestions).
+		Scan(&ids).Error; err != nil {
+		fmt.Println("❌ Popular accounts refresh failed:", err)
+		return
+	}
+
+	popularMu.Lock()
+	popularAccounts = ids
+	popularMu.Unlock()
+}
+
+// PopularAccounts returns the ids loaded by RefreshPopularAccounts, most followed first.
+func PopularAccounts() []uint {
+	popularMu.RLock()
+	defer popularMu.RUnlock()
+	return append([]uint(nil), popularAccounts...)
+}
+
+// RefreshSuggestions recomputes caches older than config.SuggestionsTTL.
+// Users who never asked for suggestions are computed on first request instead.
+func RefreshSuggestions() {
+	var ids []uint
+	if err := config.DB.Model(&models.UserSuggestion{}).
+		Select("user_id").
+		Group("user_id").
+		Having("MAX(computed_at) < ?", time.Now().Add(-config.SuggestionsTTL)).
+		Limit(500).
+		Pluck("user_id", &ids).Error; err != nil {
+		fmt.Println("❌ Suggestion refresh lookup failed:", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := ComputeSuggestions(id); err != nil {
+			fmt.Printf("❌ Suggestions for user %d failed: %v\n", id, err)
+		}
+	}
+}
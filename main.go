@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"story-backend/config"
+	"story-backend/internal"
+	appmw "story-backend/middleware"
 	"story-backend/routes"
 
 	"github.com/labstack/echo/v4"
@@ -16,9 +18,27 @@ func main() {
 	// Connect to DB
 	config.ConnectDB()
 
+	// Rate limiter store (memory or postgres)
+	appmw.InitRateLimiter()
+
+	// Background jobs (expired stories, account purge)
+	internal.StartJobs()
+
 	// Init Echo
 	e := echo.New()
 
+	// Client IP for rate limits and audit logs. Only trust X-Forwarded-For
+	// from our own proxies, otherwise clients could pick their own IP.
+	if len(config.TrustedProxies) > 0 {
+		opts := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, ipNet := range config.TrustedProxies {
+			opts = append(opts, echo.TrustIPRange(ipNet))
+		}
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(opts...)
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
@@ -26,7 +46,15 @@ func main() {
 	// Routes
 	routes.AuthRoutes(e)
 	routes.StoryRoutes(e)
+	routes.PostRoutes(e)
 	routes.FollowRoutes(e)
+	routes.MeRoutes(e)
+	routes.UserRoutes(e)
+	routes.InsightsRoutes(e)
+	routes.HashtagRoutes(e)
+	routes.ExploreRoutes(e)
+	routes.ModerationRoutes(e)
+	routes.AdminRoutes(e)
 	// Start server
 	log.Println("🚀 Server started at :8080")
 	if err := e.Start("192.168.0.111:8080"); err != nil {
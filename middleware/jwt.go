@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"net/http"
+	"story-backend/config"
+	"story-backend/models"
 	"story-backend/utils"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -33,8 +36,28 @@ func JWTAuth() echo.MiddlewareFunc {
 				return c.JSON(http.StatusUnauthorized, echo.Map{"error": err.Error()})
 			}
 
-			// 5. Store in context
+			// 5. Reject revoked tokens, accounts pending deletion, suspended and banned accounts
+			var user models.User
+			if err := config.DB.Select("id", "token_version", "deletion_requested_at", "suspended_until", "banned_at").
+				First(&user, userID).Error; err != nil {
+				return c.JSON(http.StatusUnauthorized, echo.Map{"error": "user not found"})
+			}
+			if user.TokenVersion != utils.ExtractTokenVersion(claims) {
+				return c.JSON(http.StatusUnauthorized, echo.Map{"error": "token revoked"})
+			}
+			if user.DeletionRequestedAt != nil {
+				return c.JSON(http.StatusUnauthorized, echo.Map{"error": "account scheduled for deletion"})
+			}
+			if user.BannedAt != nil {
+				return c.JSON(http.StatusForbidden, echo.Map{"error": "account banned"})
+			}
+			if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+				return c.JSON(http.StatusForbidden, echo.Map{"error": "account suspended", "suspended_until": user.SuspendedUntil})
+			}
+
+			// 6. Store in context
 			c.Set("user_id", userID)
+			c.Set("role", utils.ExtractRole(claims))
 			return next(c)
 		}
 	}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"story-backend/config"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimitResult is the outcome of taking one token from a bucket.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token (only when denied)
+}
+
+// RateLimitStore holds token buckets. Memory is fine for a single instance;
+// use the Postgres store when running multiple replicas.
+type RateLimitStore interface {
+	Take(key string, limit config.RateLimit) (RateLimitResult, error)
+}
+
+var limiterStore RateLimitStore = NewMemoryRateLimitStore()
+
+// InitRateLimiter picks the store configured in config.RateLimitStore.
+// Must run after config.ConnectDB when using "postgres".
+func InitRateLimiter() {
+	switch config.RateLimitStore {
+	case "postgres":
+		limiterStore = NewPostgresRateLimitStore(config.DB)
+	default:
+		limiterStore = NewMemoryRateLimitStore()
+	}
+	log.Printf("✅ Rate limiter using %s store", config.RateLimitStore)
+}
+
+// RateLimit limits requests for a route group (see config.RateLimits).
+// Keyed by user_id when JWTAuth ran before it, otherwise by client IP.
+func RateLimit(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			limit, ok := config.RateLimits[group]
+			if !ok {
+				return next(c)
+			}
+
+			key := fmt.Sprintf("%s:ip:%s", group, c.RealIP())
+			if uid, ok := c.Get("user_id").(uint); ok && uid != 0 {
+				key = fmt.Sprintf("%s:user:%d", group, uid)
+			}
+
+			res, err := limiterStore.Take(key, limit)
+			if err != nil {
+				// Fail open: a broken store shouldn't take the API down
+				log.Println("rate limit store error:", err)
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				return c.JSON(http.StatusTooManyRequests, echo.Map{"error": "rate limit exceeded"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// -------------------- Helpers --------------------
+
+// takeToken refills a bucket for the time elapsed since last and tries to
+// take one token. Returns the new token count.
+func takeToken(tokens float64, last, now time.Time, limit config.RateLimit) (float64, RateLimitResult) {
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Per.Seconds() // tokens per second
+
+	if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+		tokens = math.Min(capacity, tokens+elapsed*rate)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return tokens, bucketResult(tokens, allowed, limit)
+}
+
+// bucketResult describes a bucket holding tokens right after a take.
+func bucketResult(tokens float64, allowed bool, limit config.RateLimit) RateLimitResult {
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Per.Seconds()
+
+	res := RateLimitResult{Allowed: allowed}
+	if !allowed {
+		res.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.Reset = secondsToDuration((capacity - tokens) / rate)
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"story-backend/config"
+	"story-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// -------------------- In-memory store --------------------
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	per    time.Duration
+}
+
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	takes   int
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, limit config.RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Requests), last: now, per: limit.Per}
+		s.buckets[key] = b
+	}
+
+	var res RateLimitResult
+	b.tokens, res = takeToken(b.tokens, b.last, now, limit)
+	b.last = now
+
+	// Every so often drop buckets that have been idle long enough to be full
+	s.takes++
+	if s.takes%1000 == 0 {
+		s.sweep(now)
+	}
+	return res, nil
+}
+
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		if now.Sub(b.last) > b.per {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+// -------------------- Postgres store --------------------
+
+type PostgresRateLimitStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresRateLimitStore(db *gorm.DB) *PostgresRateLimitStore {
+	return &PostgresRateLimitStore{db: db}
+}
+
+// Take refills and takes in one statement, so concurrent replicas can't
+// both spend the same token, even on the first request for a key. Same
+// math as takeToken, using the database clock.
+func (s *PostgresRateLimitStore) Take(key string, limit config.RateLimit) (RateLimitResult, error) {
+	const refilled = `LEAST(@capacity, rate_limit_buckets.tokens +
+		GREATEST(0, EXTRACT(EPOCH FROM now() - rate_limit_buckets.refilled_at)) * @rate)`
+
+	var b models.RateLimitBucket
+	err := s.db.Raw(`
+		INSERT INTO rate_limit_buckets (key, tokens, refilled_at, allowed)
+		VALUES (@key, @capacity - 1, now(), true)
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE WHEN `+refilled+` >= 1 THEN `+refilled+` - 1 ELSE `+refilled+` END,
+			allowed = `+refilled+` >= 1,
+			refilled_at = now()
+		RETURNING tokens, allowed`,
+		map[string]interface{}{
+			"key":      key,
+			"capacity": float64(limit.Requests),
+			"rate":     float64(limit.Requests) / limit.Per.Seconds(),
+		}).
+		Scan(&b).Error
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return bucketResult(b.Tokens, b.Allowed, limit), nil
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"story-backend/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRole lets through callers whose role claim is one of roles. Use after JWTAuth.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !allowed[utils.GetRole(c)] {
+				return c.JSON(http.StatusForbidden, echo.Map{"error": "insufficient role"})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// AdminAuditLog records every /admin action, reads of private account data
+// included. Rows are never updated or deleted, and have no foreign keys so
+// the log survives account purges.
+type AdminAuditLog struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	AdminID      uint      `gorm:"not null;index" json:"admin_id"`       // 0 for system actions, e.g. the startup bootstrap
+	Action       string    `gorm:"size:30;not null;index" json:"action"` // e.g. "view_user", "ban", "delete_post"
+	TargetUserID *uint     `gorm:"index" json:"target_user_id,omitempty"`
+	TargetType   string    `gorm:"size:20" json:"target_type,omitempty"` // "user" | "post" | "story"
+	TargetID     *uint     `json:"target_id,omitempty"`
+	OldValue     string    `gorm:"size:50" json:"old_value,omitempty"` // e.g. previous role
+	NewValue     string    `gorm:"size:50" json:"new_value,omitempty"`
+	Reason       string    `gorm:"type:text" json:"reason"`
+	IP           string    `gorm:"size:64" json:"ip"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Block struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BlockerID uint      `gorm:"not null;uniqueIndex:idx_blocker_blocked" json:"blocker_id"`       // who blocks
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_blocker_blocked;index" json:"blocked_id"` // who is blocked
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	Blocker User `gorm:"foreignKey:BlockerID" json:"-"`
+	Blocked User `gorm:"foreignKey:BlockedID" json:"-"`
+}
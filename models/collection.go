@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Collection is a named, private group of saved posts.
+type Collection struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_collection_name" json:"user_id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_user_collection_name" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// CollectionPost puts a saved post in a collection. A post can be in
+// several collections.
+type CollectionPost struct {
+	CollectionID uint      `gorm:"primaryKey" json:"collection_id"`
+	PostID       uint      `gorm:"primaryKey;index" json:"post_id"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	Collection Collection `gorm:"foreignKey:CollectionID" json:"-"`
+	Post       Post       `gorm:"foreignKey:PostID" json:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type DataExport struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Status      string     `gorm:"size:20;not null;default:'pending'" json:"status"` // "pending" | "running" | "ready" | "failed" | "expired"
+	TokenHash   *string    `gorm:"size:64;uniqueIndex" json:"-"`                     // download link token (hashed)
+	FilePath    string     `gorm:"type:text" json:"-"`
+	ExpiresAt   *time.Time `json:"expires_at"` // download link expiry
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt   *time.Time `json:"-"` // when a worker claimed it
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// -------- Relations --------
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
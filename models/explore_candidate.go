@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ExploreCandidate is a precomputed explore post. The pool is shared by all
+// users and rebuilt by internal.RefreshExploreCandidates; per-viewer
+// filtering happens at read time.
+type ExploreCandidate struct {
+	PostID     uint      `gorm:"primaryKey" json:"post_id"`
+	AuthorID   uint      `gorm:"not null;index" json:"author_id"`
+	Score      float64   `gorm:"not null;index" json:"score"` // engagement velocity with the per-author diversity penalty applied
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+
+	// -------- Relations --------
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+}
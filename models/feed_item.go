@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// FeedItem is one entry of a user's home timeline, written when a followed
+// author publishes (fan-out on write). Exactly one of PostID and StoryID is
+// set. CreatedAt is the content's publish time.
+type FeedItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_feed_post;uniqueIndex:idx_feed_story;index:idx_feed_user_time,priority:1" json:"user_id"` // timeline owner
+	AuthorID  uint      `gorm:"not null;index" json:"author_id"`
+	PostID    *uint     `gorm:"uniqueIndex:idx_feed_post;index" json:"post_id,omitempty"`
+	StoryID   *uint     `gorm:"uniqueIndex:idx_feed_story;index" json:"story_id,omitempty"`
+	CreatedAt time.Time `gorm:"not null;index;index:idx_feed_user_time,priority:2" json:"created_at"`
+
+	// -------- Relations --------
+	User   User  `gorm:"foreignKey:UserID" json:"-"`
+	Author User  `gorm:"foreignKey:AuthorID" json:"-"`
+	Post   Post  `gorm:"foreignKey:PostID" json:"-"`
+	Story  Story `gorm:"foreignKey:StoryID" json:"-"`
+}
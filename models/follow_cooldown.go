@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// FollowCooldown stops a removed follower from following/requesting again until Until.
+type FollowCooldown struct {
+	ID      uint      `gorm:"primaryKey" json:"id"`
+	OwnerID uint      `gorm:"not null;uniqueIndex:idx_cooldown_owner_user" json:"owner_id"` // account that removed the follower
+	UserID  uint      `gorm:"not null;uniqueIndex:idx_cooldown_owner_user" json:"user_id"`  // removed follower
+	Until   time.Time `gorm:"not null" json:"until"`
+
+	// -------- Relations --------
+	Owner User `gorm:"foreignKey:OwnerID" json:"-"`
+	User  User `gorm:"foreignKey:UserID" json:"-"`
+}
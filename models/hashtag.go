@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Hashtag names are stored lowercase without the leading '#'.
+type Hashtag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PostHashtag links a post to a hashtag parsed from its caption.
+type PostHashtag struct {
+	PostID    uint `gorm:"primaryKey" json:"post_id"`
+	HashtagID uint `gorm:"primaryKey;index" json:"hashtag_id"`
+
+	// -------- Relations --------
+	Post    Post    `gorm:"foreignKey:PostID" json:"-"`
+	Hashtag Hashtag `gorm:"foreignKey:HashtagID" json:"-"`
+}
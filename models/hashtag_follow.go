@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// HashtagFollow brings posts with the hashtag into the user's feed.
+type HashtagFollow struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_hashtag" json:"user_id"`
+	HashtagID uint      `gorm:"not null;uniqueIndex:idx_user_hashtag;index" json:"hashtag_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	User    User    `gorm:"foreignKey:UserID" json:"-"`
+	Hashtag Hashtag `gorm:"foreignKey:HashtagID" json:"-"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Mention tags a user in a post caption or a story. Exactly one of PostID
+// and StoryID is set.
+type Mention struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index;uniqueIndex:idx_mention_post;uniqueIndex:idx_mention_story" json:"user_id"` // who is mentioned
+	AuthorID  uint       `gorm:"not null;index" json:"author_id"`
+	PostID    *uint      `gorm:"index;uniqueIndex:idx_mention_post" json:"post_id,omitempty"`
+	StoryID   *uint      `gorm:"index;uniqueIndex:idx_mention_story" json:"story_id,omitempty"`
+	RemovedAt *time.Time `json:"-"` // the mentioned user removed themselves; kept so edits don't re-add them
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	User   User  `gorm:"foreignKey:UserID" json:"-"`
+	Author User  `gorm:"foreignKey:AuthorID" json:"-"`
+	Post   Post  `gorm:"foreignKey:PostID" json:"-"`
+	Story  Story `gorm:"foreignKey:StoryID" json:"-"`
+}
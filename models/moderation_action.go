@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ModerationAction is one entry of the moderation audit trail. Rows are
+// never updated or deleted, and have no foreign keys so the trail survives
+// account purges.
+type ModerationAction struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ModeratorID  uint       `gorm:"not null;index" json:"moderator_id"`
+	ReportID     *uint      `gorm:"index" json:"report_id,omitempty"`
+	Action       string     `gorm:"size:30;not null" json:"action"` // "remove_content" | "warn" | "suspend" | "dismiss", plus "ban" | "unban" | "unsuspend" from /admin
+	TargetUserID uint       `gorm:"not null;index" json:"target_user_id"`
+	TargetType   string     `gorm:"size:20" json:"target_type,omitempty"`
+	TargetID     *uint      `json:"target_id,omitempty"`
+	Notes        string     `gorm:"type:text" json:"notes"`
+	Until        *time.Time `json:"until,omitempty"` // end of a suspension
+	CreatedAt    time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Mute quiets an account without unfollowing. The muted user is never told.
+type Mute struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MuterID   uint      `gorm:"not null;uniqueIndex:idx_muter_muted" json:"muter_id"` // who mutes
+	MutedID   uint      `gorm:"not null;uniqueIndex:idx_muter_muted" json:"muted_id"` // who is muted
+	Stories   bool      `gorm:"not null;default:false" json:"stories"`                // push their story ring to the end
+	Posts     bool      `gorm:"not null;default:false" json:"posts"`                  // hide their posts from the feed
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	Muter User `gorm:"foreignKey:MuterID" json:"-"`
+	Muted User `gorm:"foreignKey:MutedID" json:"-"`
+}
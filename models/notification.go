@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"` // recipient
+	Type       string     `gorm:"size:40;not null" json:"type"`  // e.g. "data_export_ready"
+	ActorID    *uint      `json:"actor_id,omitempty"`            // who triggered it, if anyone
+	EntityType string     `gorm:"size:20" json:"entity_type,omitempty"`
+	EntityID   *uint      `json:"entity_id,omitempty"`
+	Message    string     `gorm:"type:text" json:"message"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+
+	// -------- Relations --------
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -6,10 +6,19 @@ type Post struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	UserID    uint      `gorm:"not null;index" json:"user_id"`
 	Caption   string    `gorm:"type:text" json:"caption"`
-	MediaURL  string    `gorm:"type:text;not null" json:"media_url"`
-	MediaType string    `gorm:"size:20;not null" json:"media_type"` // "image" | "video"
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	MediaURL  string    `gorm:"type:text;not null" json:"media_url"` // first carousel item, kept for older clients
+	MediaType string    `gorm:"size:20;not null" json:"media_type"`  // "image" | "video"
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`    // publish time once published
+
+	// -------- Publishing --------
+	Status    string     `gorm:"size:20;not null;default:'published';index" json:"status"` // "draft" | "scheduled" | "published"
+	PublishAt *time.Time `gorm:"index" json:"publish_at,omitempty"`
+
+	// -------- Fan-out --------
+	FannedOutAt  *time.Time `gorm:"index" json:"-"`                  // copied to followers' timelines (or marked read-time)
+	FanoutOnRead bool       `gorm:"not null;default:false" json:"-"` // author too big to fan out; read at request time
 
 	// -------- Relations --------
-	User User `gorm:"foreignKey:UserID" json:"user"`
+	User  User        `gorm:"foreignKey:UserID" json:"user"`
+	Media []PostMedia `gorm:"foreignKey:PostID" json:"media"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type PostLike struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_post_liker;index:idx_post_like_time,priority:1" json:"post_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_post_liker;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_post_like_time,priority:2" json:"created_at"`
+
+	// -------- Relations --------
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// PostMedia is one item of a post's carousel, ordered by Position.
+type PostMedia struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	PostID    uint   `gorm:"not null;uniqueIndex:idx_post_media_position" json:"post_id"`
+	Position  int    `gorm:"not null;uniqueIndex:idx_post_media_position" json:"position"` // 0-based
+	MediaURL  string `gorm:"type:text;not null" json:"media_url"`
+	MediaType string `gorm:"size:20;not null" json:"media_type"` // e.g. "image" | "video"
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	AltText   string `gorm:"size:1000" json:"alt_text,omitempty"`
+
+	// -------- Relations --------
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+}
+
+func (PostMedia) TableName() string {
+	return "post_media"
+}
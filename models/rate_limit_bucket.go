@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RateLimitBucket backs the Postgres rate limit store (shared across replicas).
+type RateLimitBucket struct {
+	Key        string    `gorm:"primaryKey;size:191" json:"key"`
+	Tokens     float64   `gorm:"not null" json:"tokens"`
+	RefilledAt time.Time `gorm:"not null;index" json:"refilled_at"`
+	Allowed    bool      `gorm:"not null;default:true" json:"allowed"` // outcome of the last take
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Report flags a user, story, post or comment for moderation. Snapshot holds
+// the reported content as JSON at report time, so it can still be reviewed
+// after the content is edited or removed.
+//
+// There are deliberately no foreign keys: reports outlive the content and
+// accounts they refer to.
+type Report struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ReporterID   uint       `gorm:"not null;index" json:"reporter_id"`
+	TargetType   string     `gorm:"size:20;not null;index:idx_report_target" json:"target_type"` // "user" | "story" | "post" | "comment"
+	TargetID     uint       `gorm:"not null;index:idx_report_target" json:"target_id"`
+	TargetUserID uint       `gorm:"not null;index" json:"target_user_id"` // whose content it is
+	Reason       string     `gorm:"size:30;not null" json:"reason"`
+	Notes        string     `gorm:"type:text" json:"notes"`
+	Snapshot     string     `gorm:"type:jsonb;not null" json:"snapshot"`
+	Status       string     `gorm:"size:20;not null;default:'open';index" json:"status"` // "open" | "actioned" | "dismissed"
+	ResolvedBy   *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SavedPost is a private bookmark.
+type SavedPost struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_saver_post" json:"user_id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_saver_post;index" json:"post_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	User User `gorm:"foreignKey:UserID" json:"-"`
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+}
@@ -10,6 +10,18 @@ type Story struct {
 	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
 	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
 
+	// -------- Publishing --------
+	Status     string     `gorm:"size:20;not null;default:'published';index" json:"status"` // "draft" | "scheduled" | "published"
+	PublishAt  *time.Time `gorm:"index" json:"publish_at,omitempty"`
+	TTLMinutes int        `gorm:"not null;default:1440" json:"ttl_minutes"` // ExpiresAt = publish time + TTL
+
+	// -------- Fan-out --------
+	FannedOutAt  *time.Time `gorm:"index" json:"-"`                  // copied to followers' timelines (or marked read-time)
+	FanoutOnRead bool       `gorm:"not null;default:false" json:"-"` // author too big to fan out; read at request time
+
+	// Set once the story leaves the feed and moves to the owner's archive
+	ArchivedAt *time.Time `gorm:"index" json:"archived_at,omitempty"`
+
 	// -------- Relations --------
 	User  User        `gorm:"foreignKey:UserID" json:"user"`
 	Views []StoryView `gorm:"foreignKey:StoryID" json:"views,omitempty"`
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// StoryEvent is a client-reported viewer interaction, used for creator insights.
+type StoryEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	StoryID   uint      `gorm:"not null;index:idx_story_event_type" json:"story_id"`
+	ViewerID  uint      `gorm:"not null;index" json:"viewer_id"`
+	Type      string    `gorm:"size:20;not null;index:idx_story_event_type" json:"type"` // "complete" | "exit" | "tap_forward" | "tap_back"
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	Story  Story `gorm:"foreignKey:StoryID" json:"-"`
+	Viewer User  `gorm:"foreignKey:ViewerID" json:"-"`
+}
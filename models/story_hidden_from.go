@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// StoryHiddenFrom hides all of an owner's stories from one viewer.
+type StoryHiddenFrom struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OwnerID   uint      `gorm:"not null;uniqueIndex:idx_story_hidden_owner_viewer" json:"owner_id"`
+	ViewerID  uint      `gorm:"not null;uniqueIndex:idx_story_hidden_owner_viewer;index" json:"viewer_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	Owner  User `gorm:"foreignKey:OwnerID" json:"-"`
+	Viewer User `gorm:"foreignKey:ViewerID" json:"-"`
+}
+
+func (StoryHiddenFrom) TableName() string {
+	return "story_hidden_from"
+}
@@ -9,8 +9,33 @@ type User struct {
 	Password   string    `gorm:"not null" json:"-"`
 	ProfilePic *string   `gorm:"type:text" json:"profile_pic,omitempty"`
 	Type       string    `gorm:"type:text;default:'public'" json:"type"`
+	Role       string    `gorm:"size:20;not null;default:'user'" json:"-"` // "user" | "moderator" | "admin"
 	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
 
+	// -------- Profile --------
+	DisplayName string `gorm:"size:100" json:"display_name"`
+	Bio         string `gorm:"type:text" json:"bio"`
+	Website     string `gorm:"type:text" json:"website"`
+	Pronouns    string `gorm:"size:40" json:"pronouns"`
+
+	// -------- Settings --------
+	StoryArchiveEnabled bool   `gorm:"not null;default:true" json:"story_archive_enabled"`
+	MentionPolicy       string `gorm:"size:20;not null;default:'everyone'" json:"mention_policy"` // "everyone" | "following" | "nobody"
+
+	// -------- Activity --------
+	FeedVisitedAt          *time.Time `json:"-"` // where the client last reached the caught-up marker
+	SuggestionsRequestedAt *time.Time `json:"-"` // waiting for the suggestions job
+	SuggestionsComputedAt  *time.Time `json:"-"`
+
+	// -------- Account security --------
+	TokenVersion        uint       `gorm:"not null;default:0" json:"-"` // bump to revoke all issued JWTs
+	PendingEmail        *string    `gorm:"size:120" json:"-"`           // waiting for verification
+	EmailVerifyHash     *string    `gorm:"size:64;index" json:"-"`
+	EmailVerifyExpires  *time.Time `json:"-"`
+	DeletionRequestedAt *time.Time `gorm:"index" json:"-"` // soft delete; purged after the grace period
+	SuspendedUntil      *time.Time `json:"-"`              // set by moderators; no access until then
+	BannedAt            *time.Time `json:"-"`              // set by admins; no access until lifted
+
 	// -------- Relations --------
 	Posts      []Post      `gorm:"foreignKey:UserID" json:"posts,omitempty"`
 	Stories    []Story     `gorm:"foreignKey:UserID" json:"stories,omitempty"`
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// UserSuggestion is a cached "people you may know" entry, rebuilt by a background job.
+type UserSuggestion struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_user_suggested" json:"user_id"`      // who sees the suggestion
+	SuggestedID uint      `gorm:"not null;uniqueIndex:idx_user_suggested" json:"suggested_id"` // who is suggested
+	Reason      string    `gorm:"size:30;not null" json:"reason"`                              // "mutual_follows" | "follows_you" | "popular"
+	MutualCount int       `gorm:"not null;default:0" json:"mutual_count"`
+	Score       float64   `gorm:"not null" json:"score"`
+	ComputedAt  time.Time `gorm:"not null;index" json:"computed_at"`
+
+	// -------- Relations --------
+	User      User `gorm:"foreignKey:UserID" json:"-"`
+	Suggested User `gorm:"foreignKey:SuggestedID" json:"-"`
+}
+
+// SuggestionDismissal hides a suggested user for good.
+type SuggestionDismissal struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_dismissal_user_suggested" json:"user_id"`
+	SuggestedID uint      `gorm:"not null;uniqueIndex:idx_dismissal_user_suggested" json:"suggested_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// -------- Relations --------
+	User      User `gorm:"foreignKey:UserID" json:"-"`
+	Suggested User `gorm:"foreignKey:SuggestedID" json:"-"`
+}
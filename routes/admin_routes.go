@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func AdminRoutes(e *echo.Echo) {
+	admin := e.Group("/admin", middleware.JWTAuth(), middleware.RequireRole("admin"))
+
+	admin.GET("/users", controllers.AdminSearchUsers)
+	admin.GET("/users/:id", controllers.AdminGetUser)
+	admin.PUT("/users/:id/role", controllers.AdminSetRole)
+	admin.POST("/users/:id/suspend", controllers.AdminSuspendUser)
+	admin.DELETE("/users/:id/suspend", controllers.AdminUnsuspendUser)
+	admin.POST("/users/:id/ban", controllers.AdminBanUser)
+	admin.DELETE("/users/:id/ban", controllers.AdminUnbanUser)
+	admin.POST("/users/:id/2fa/reset", controllers.AdminReset2FA) // 501 until 2FA exists
+
+	admin.DELETE("/posts/:id", controllers.AdminDeletePost)
+	admin.DELETE("/stories/:id", controllers.AdminDeleteStory)
+
+	admin.GET("/audit", controllers.GetAdminAuditLog)
+}
@@ -10,12 +10,18 @@ import (
 func AuthRoutes(e *echo.Echo) {
 	auth := e.Group("/auth")
 
-	// Public routes
-	auth.POST("/signup", controllers.Signup)
-	auth.POST("/login", controllers.Login)
+	// Public routes, limited per IP against credential stuffing
+	auth.POST("/signup", controllers.Signup, middleware.RateLimit("auth"))
+	auth.POST("/login", controllers.Login, middleware.RateLimit("auth"))
+	auth.GET("/email/verify", controllers.VerifyEmail) // link from the verification email
 
 	// Protected route to get current logged-in user
 	auth.GET("/me", controllers.Me, middleware.JWTAuth())
 	auth.PATCH("/toggle", controllers.ToggleAccountType, middleware.JWTAuth())
 
+	// Account management
+	auth.PATCH("/password", controllers.ChangePassword, middleware.JWTAuth())
+	auth.PATCH("/email", controllers.ChangeEmail, middleware.JWTAuth())
+	auth.DELETE("/account", controllers.DeleteAccount, middleware.JWTAuth())
+
 }
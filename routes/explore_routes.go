@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func ExploreRoutes(e *echo.Echo) {
+	e.GET("/explore", controllers.GetExplore, middleware.JWTAuth())
+}
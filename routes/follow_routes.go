@@ -8,16 +8,27 @@ import (
 )
 
 func FollowRoutes(e *echo.Echo) {
-	follow := e.Group("/follow", middleware.JWTAuth())
+	follow := e.Group("/follow", middleware.JWTAuth(), middleware.RateLimit("follow"))
 
-	follow.POST("/:identifier", controllers.FollowUser)    // Follow a user (by id or username)
-	follow.DELETE("/:id", controllers.UnfollowUser)        // Unfollow a user
-	follow.GET("/following/:id", controllers.GetFollowing) // Who this user follows
-	follow.GET("/followers/:id", controllers.GetFollowers) // Who follows this user
+	follow.POST("/:identifier", controllers.FollowUser)         // Follow a user (by id or username)
+	follow.DELETE("/:id", controllers.UnfollowUser)             // Unfollow a user
+	follow.GET("/following/:id", controllers.GetFollowing)      // Who this user follows
+	follow.GET("/followers/:id", controllers.GetFollowers)      // Who follows this user
+	follow.DELETE("/followers/:id", controllers.RemoveFollower) // Remove one of my followers
 
 	// NEW for private accounts:
 	follow.GET("/requests", controllers.GetFollowRequests)          // list requests
 	follow.POST("/requests/:id", controllers.AcceptFollowRequest)   // accept request
 	follow.DELETE("/requests/:id", controllers.RejectFollowRequest) // reject request
 
+	// Bulk handling of incoming requests (per-item results)
+	follow.POST("/requests/accept", controllers.AcceptFollowRequests)        // accept listed ids
+	follow.POST("/requests/accept-all", controllers.AcceptAllFollowRequests) // accept everything
+	follow.POST("/requests/reject", controllers.RejectFollowRequests)        // reject listed ids
+	follow.POST("/requests/reject-all", controllers.RejectAllFollowRequests) // reject everything
+
+	// Requests I sent
+	follow.GET("/requests/sent", controllers.GetSentFollowRequests)      // list pending outgoing
+	follow.DELETE("/requests/sent/:id", controllers.CancelFollowRequest) // cancel by followee id
+
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func HashtagRoutes(e *echo.Echo) {
+	tags := e.Group("/tags", middleware.JWTAuth())
+	tags.GET("/following", controllers.GetFollowedHashtags)
+	tags.GET("/:name", controllers.GetHashtag)
+	tags.POST("/:name/follow", controllers.FollowHashtag)
+	tags.DELETE("/:name/follow", controllers.UnfollowHashtag)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func InsightsRoutes(e *echo.Echo) {
+	insights := e.Group("/insights", middleware.JWTAuth())
+	insights.GET("/stories", controllers.GetStoriesInsights) // ?days=7|30
+
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func MeRoutes(e *echo.Echo) {
+	me := e.Group("/me", middleware.JWTAuth())
+
+	me.PATCH("/profile", controllers.UpdateProfile)
+	me.GET("/settings", controllers.GetSettings)
+	me.PATCH("/settings", controllers.UpdateSettings)
+	me.GET("/mutes", controllers.GetMutes)
+	me.GET("/mentions", controllers.GetMentions)
+	me.DELETE("/mentions/:id", controllers.RemoveMention)
+
+	// Saved posts and collections
+	me.GET("/saved", controllers.GetSavedPosts)
+	me.GET("/collections", controllers.GetCollections)
+	me.POST("/collections", controllers.CreateCollection)
+	me.GET("/collections/:id", controllers.GetCollection)
+	me.PATCH("/collections/:id", controllers.RenameCollection)
+	me.DELETE("/collections/:id", controllers.DeleteCollection)
+	me.DELETE("/collections/:id/posts/:post_id", controllers.RemoveFromCollection)
+
+	// Personal data export
+	me.POST("/export", controllers.RequestDataExport)
+	me.GET("/export/:id", controllers.GetDataExport)
+
+	// Notifications
+	me.GET("/notifications", controllers.GetNotifications)
+	me.PATCH("/notifications/read", controllers.MarkNotificationsRead)
+
+	// Public: download link issued by GET /me/export/:id
+	e.GET("/exports/:token", controllers.DownloadDataExport)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func ModerationRoutes(e *echo.Echo) {
+	e.POST("/reports", controllers.CreateReport, middleware.JWTAuth())
+
+	mod := e.Group("/moderation", middleware.JWTAuth(), middleware.RequireRole("moderator", "admin"))
+	mod.GET("/reports", controllers.GetReports)
+	mod.GET("/reports/:id", controllers.GetReport)
+	mod.POST("/reports/:id/actions", controllers.ActOnReport)
+	mod.GET("/actions", controllers.GetModerationActions)
+}
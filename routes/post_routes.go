@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func PostRoutes(e *echo.Echo) {
+	posts := e.Group("/posts", middleware.JWTAuth())
+	posts.POST("/add", controllers.AddPost)
+	posts.GET("/feed", controllers.GetPostsFeed)
+	posts.POST("/feed/caught-up", controllers.MarkFeedCaughtUp)
+	posts.GET("/user/:id", controllers.GetUserPosts)
+	posts.GET("/scheduled", controllers.GetScheduledPosts)
+	posts.PATCH("/scheduled/:id", controllers.UpdateScheduledPost)
+	posts.DELETE("/scheduled/:id", controllers.CancelScheduledPost)
+	posts.PATCH("/:id", controllers.EditPost)
+	posts.DELETE("/:id", controllers.DeletePost)
+	posts.POST("/:id/like", controllers.LikePost)
+	posts.DELETE("/:id/like", controllers.UnlikePost)
+	posts.POST("/:id/save", controllers.SavePost)
+	posts.DELETE("/:id/save", controllers.UnsavePost)
+
+}
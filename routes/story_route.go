@@ -8,12 +8,27 @@ import (
 )
 
 func StoryRoutes(e *echo.Echo) {
-	stories := e.Group("/stories", middleware.JWTAuth())
+	stories := e.Group("/stories", middleware.JWTAuth(), middleware.RateLimit("stories"))
 	stories.POST("/add", controllers.AddStory)
 	stories.GET("/feed", controllers.GetStoriesFeed)
 	stories.GET("/user/:id", controllers.GetUserStories)
+	stories.GET("/archive", controllers.GetStoryArchive)
+	stories.GET("/archive/calendar", controllers.GetStoryArchiveCalendar)
+
+	// Drafts and scheduled stories
+	stories.GET("/scheduled", controllers.GetScheduledStories)
+	stories.PATCH("/scheduled/:id", controllers.UpdateScheduledStory)
+	stories.DELETE("/scheduled/:id", controllers.CancelScheduledStory)
+
 	stories.DELETE("/:id", controllers.DeleteStory)
 	stories.POST("/:id/view", controllers.ViewStory)
 	stories.GET("/:id/views", controllers.GetStoryViews)
+	stories.POST("/:id/events", controllers.RecordStoryEvent)
+	stories.GET("/:id/insights", controllers.GetStoryInsights)
+
+	// Hide my stories from specific viewers
+	stories.GET("/hidden", controllers.GetStoryHiddenFrom)
+	stories.POST("/hidden/:id", controllers.HideStoriesFrom)
+	stories.DELETE("/hidden/:id", controllers.UnhideStoriesFrom)
 
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"story-backend/controllers"
+	"story-backend/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func UserRoutes(e *echo.Echo) {
+	users := e.Group("/users", middleware.JWTAuth())
+
+	users.GET("/search", controllers.SearchUsers)         // ?q=&cursor=&limit=
+	users.GET("/suggestions", controllers.GetSuggestions) // People you may know
+	users.POST("/suggestions/:id/dismiss", controllers.DismissSuggestion)
+	users.GET("/:identifier", controllers.GetUserProfile) // Profile by id or username
+	users.GET("/:identifier/mutuals", controllers.GetMutuals)
+	users.POST("/:identifier/block", controllers.BlockUser)
+	users.DELETE("/:identifier/block", controllers.UnblockUser)
+	users.POST("/:identifier/mute", controllers.MuteUser) // {"stories": bool, "posts": bool}
+	users.DELETE("/:identifier/mute", controllers.UnmuteUser)
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//
+// ------------------ CURSOR HELPERS ------------------
+//
+
+// EncodeCursor packs a (score, id) keyset position into an opaque string.
+func EncodeCursor(score float64, id uint) string {
+	raw := strconv.FormatFloat(score, 'g', -1, 64) + "|" + strconv.FormatUint(uint64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor is the inverse of EncodeCursor.
+func DecodeCursor(cursor string) (float64, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	score, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid cursor")
+	}
+	return score, uint(id), nil
+}
+
+// EncodePoolCursor is EncodeCursor for keysets over a table that gets
+// rebuilt: version identifies the build the position belongs to.
+func EncodePoolCursor(version int64, score float64, id uint) string {
+	raw := strconv.FormatInt(version, 10) + "|" + strconv.FormatFloat(score, 'g', -1, 64) + "|" + strconv.FormatUint(uint64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodePoolCursor is the inverse of EncodePoolCursor.
+func DecodePoolCursor(cursor string) (int64, float64, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return 0, 0, 0, errors.New("invalid cursor")
+	}
+	version, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid cursor")
+	}
+	score, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid cursor")
+	}
+	return version, score, uint(id), nil
+}
+
+// PageLimit parses a ?limit= value, falling back to def and capping at max.
+func PageLimit(raw string, def, max int) int {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return def
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+//
+// ------------------ HASHTAG HELPERS ------------------
+//
+
+var hashtagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+const (
+	MaxHashtagLength   = 100
+	MaxHashtagsPerPost = 30
+)
+
+// ParseHashtags returns the distinct, lowercased hashtags in text, in order
+// of first appearance and without the '#'.
+func ParseHashtags(text string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, m := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := NormalizeHashtag(m[1])
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == MaxHashtagsPerPost {
+			break
+		}
+	}
+	return tags
+}
+
+// NormalizeHashtag lowercases a tag and strips a leading '#'. It returns ""
+// for anything that isn't a valid tag.
+func NormalizeHashtag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || len(tag) > MaxHashtagLength || hashtagPattern.FindString("#"+tag) != "#"+tag {
+		return ""
+	}
+	return tag
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"story-backend/config"
+	"story-backend/models"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
@@ -30,13 +31,18 @@ func CheckPassword(hashed, plain string) error {
 // ------------------ JWT HELPERS ------------------
 //
 
-func GenerateJWT(userID uint) (string, error) {
-	return GenerateJWTWithExpiry(userID, 72*time.Hour)
+func GenerateJWT(user models.User) (string, error) {
+	return GenerateJWTWithExpiry(user, 72*time.Hour)
 }
 
-func GenerateJWTWithExpiry(userID uint, duration time.Duration) (string, error) {
+// GenerateJWTWithExpiry signs a token for the user. "ver" is the user's
+// TokenVersion; bumping it revokes every token issued before. Role changes
+// bump it too, so the "role" claim is never stale.
+func GenerateJWTWithExpiry(user models.User, duration time.Duration) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": userID,
+		"user_id": user.ID,
+		"ver":     user.TokenVersion,
+		"role":    user.Role,
 		"exp":     time.Now().Add(duration).Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -86,6 +92,22 @@ func ExtractUserID(claims map[string]interface{}) (uint, error) {
 	}
 }
 
+// ExtractTokenVersion returns the "ver" claim (0 for tokens issued before it existed).
+func ExtractTokenVersion(claims map[string]interface{}) uint {
+	if v, ok := claims["ver"].(float64); ok {
+		return uint(v)
+	}
+	return 0
+}
+
+// ExtractRole returns the "role" claim ("user" for tokens issued before roles existed).
+func ExtractRole(claims map[string]interface{}) string {
+	if v, ok := claims["role"].(string); ok && v != "" {
+		return v
+	}
+	return "user"
+}
+
 func SplitBearer(header string) string {
 	parts := strings.SplitN(header, " ", 2)
 	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
@@ -105,3 +127,9 @@ func GetUserID(c echo.Context) (uint, error) {
 	}
 	return uid, nil
 }
+
+// GetRole returns the caller's role as set by JWTAuth.
+func GetRole(c echo.Context) string {
+	role, _ := c.Get("role").(string)
+	return role
+}
@@ -0,0 +1,11 @@
+package utils
+
+import "log"
+
+// SendMail delivers an email to the user.
+// No provider is wired up yet, so for now it only logs the recipient and
+// subject. Bodies carry one-time links and must never reach the logs.
+func SendMail(to, subject, body string) error {
+	log.Printf("📧 mail to=%s subject=%q (body not logged)", to, subject)
+	return nil
+}
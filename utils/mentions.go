@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+//
+// ------------------ MENTION HELPERS ------------------
+//
+
+var mentionPattern = regexp.MustCompile(`(^|[^\w@])@([A-Za-z0-9_.]+)`)
+
+const MaxMentionsPerItem = 20
+
+// ParseMentions returns the distinct usernames @mentioned in text, in order
+// of first appearance and without the '@'. Email addresses don't count.
+func ParseMentions(text string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(m[2], ".")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) == MaxMentionsPerItem {
+			break
+		}
+	}
+	return names
+}